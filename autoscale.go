@@ -0,0 +1,87 @@
+package tinyPool
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// autoscaler keeps the counters of the pool at the previous autoscaling,
+// to measure the interval since.
+type autoscaler struct {
+	at        time.Time
+	submitted int64
+	completed int64
+	busy      int64
+	started   int64
+	waited    int64
+
+	// service is the mean task duration, kept over intervals in which no
+	// task completed
+	service time.Duration
+}
+
+// measure returns the lifetime counters the autoscaler measures.
+func (p *engine[T]) measure() autoscaler {
+	return autoscaler{
+		at:        time.Now(),
+		submitted: atomic.LoadInt64(&p.submitted),
+		completed: atomic.LoadInt64(&p.completed),
+		busy:      atomic.LoadInt64(&p.durations.sum),
+		started:   atomic.LoadInt64(&p.started),
+		waited:    atomic.LoadInt64(&p.waited),
+		service:   p.scaler.service,
+	}
+}
+
+// autoscale tunes the capacity for the interval since the last call. By
+// Little's law the pool needs arrival rate times mean task duration busy
+// workers to keep up, plus those which get through the backlog within an
+// interval. While tasks wait for longer than the target, or the queue
+// doesn't move, the pool grows by a quarter at least.
+func (p *engine[T]) autoscale() {
+	last := p.scaler
+	p.scaler = p.measure()
+	a := &p.scaler
+	elapsed := a.at.Sub(last.at)
+	if elapsed <= 0 {
+		return
+	}
+
+	if completed := a.completed - last.completed; completed > 0 {
+		a.service = time.Duration((a.busy - last.busy) / completed)
+	}
+	var wait time.Duration
+	if started := a.started - last.started; started > 0 {
+		wait = time.Duration((a.waited - last.waited) / started)
+	} else if p.Waiting() > 0 {
+		// no queued task started during the whole interval
+		wait = elapsed
+	}
+
+	rate := float64(a.submitted-last.submitted) / elapsed.Seconds()
+	need := rate * a.service.Seconds()
+	need += float64(p.Waiting()) * a.service.Seconds() / elapsed.Seconds()
+	size := int32(math.Ceil(need))
+
+	current := p.Cap()
+	if target := p.options.AutoscaleTargetWait; target > 0 && wait > target {
+		if grown := current + (current+3)/4; size < grown {
+			size = grown
+		}
+	}
+	if min := int32(p.options.AutoscaleMin); size < min {
+		size = min
+	}
+	if max := int32(p.options.AutoscaleMax); size > max {
+		size = max
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	if size != current {
+		p.log(LevelInfo, "autoscaled", "from", current, "to", size, "rate", rate, "service", a.service, "wait", wait)
+		p.Tune(int(size))
+	}
+}
@@ -0,0 +1,45 @@
+package tinyPool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAutoscale(t *testing.T) {
+	p, _ := NewPool(1, WithAutoscale(1, 8, 20*time.Millisecond, 5*time.Millisecond))
+	defer p.Close()
+	p.Tune(1)
+
+	// 10ms tasks at 400 per second keep 4 workers busy
+	for start := time.Now(); time.Since(start) < 300*time.Millisecond; {
+		_ = p.Submit(func() { time.Sleep(10 * time.Millisecond) })
+		time.Sleep(2500 * time.Microsecond)
+	}
+	if n := p.Cap(); n < 3 || n > 8 {
+		t.Fatalf("capacity = %d under load, want about 4", n)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for p.Cap() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("capacity = %d when idle, want the min 1", p.Cap())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAutoscaleMax(t *testing.T) {
+	p, _ := NewPool(1, WithAutoscale(1, 3, 10*time.Millisecond, time.Millisecond))
+	defer p.Close()
+	p.Tune(1)
+
+	block := make(chan struct{})
+	defer close(block)
+	for i := 0; i < 20; i++ {
+		_ = p.Submit(func() { <-block })
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := p.Cap(); n != 3 {
+		t.Fatalf("capacity = %d with a backlog, want the max 3", n)
+	}
+}
@@ -0,0 +1,51 @@
+package tinyPool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// backlogState tracks whether the queue is above the high backlog
+// watermark, see WithBacklogWatermarks.
+type backlogState struct {
+	// high is set from the moment the queue reaches the high watermark
+	// until it falls to the low one
+	high int32
+
+	// mu orders the callbacks
+	mu sync.Mutex
+}
+
+// backlog calls OnBacklogHigh once the queue reaches the high watermark,
+// then OnBacklogLow once it falls to the low one. It is called whenever a
+// task is queued or taken out of the queue.
+func (p *engine[T]) backlog() {
+	high := p.options.BacklogHigh
+	if high <= 0 {
+		return
+	}
+	b := &p.backlogged
+	above := atomic.LoadInt32(&b.high) == 1
+	if n := p.Waiting(); (above && n > p.options.BacklogLow) || (!above && n < high) {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// the queue may have moved on meanwhile
+	n := p.Waiting()
+	switch {
+	case atomic.LoadInt32(&b.high) == 0 && n >= high:
+		atomic.StoreInt32(&b.high, 1)
+		p.log(LevelWarn, "backlog high", "queued", n)
+		if fn := p.options.OnBacklogHigh; fn != nil {
+			fn(n)
+		}
+	case atomic.LoadInt32(&b.high) == 1 && n <= p.options.BacklogLow:
+		atomic.StoreInt32(&b.high, 0)
+		p.log(LevelInfo, "backlog low", "queued", n)
+		if fn := p.options.OnBacklogLow; fn != nil {
+			fn(n)
+		}
+	}
+}
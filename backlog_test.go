@@ -0,0 +1,49 @@
+package tinyPool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithBacklogWatermarks(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) func(int) {
+		return func(int) {
+			mu.Lock()
+			events = append(events, event)
+			mu.Unlock()
+		}
+	}
+	p, _ := NewPool(1, WithBacklogWatermarks(4, 1, record("high"), record("low")))
+	defer p.Close()
+	p.Tune(1)
+
+	release := saturate(p)
+	for i := 0; i < 3; i++ {
+		_ = p.Submit(func() { time.Sleep(5 * time.Millisecond) })
+	}
+	mu.Lock()
+	if len(events) != 0 {
+		t.Fatalf("events %v below the high watermark, want none", events)
+	}
+	mu.Unlock()
+
+	for i := 0; i < 5; i++ {
+		_ = p.Submit(func() { time.Sleep(5 * time.Millisecond) })
+	}
+	mu.Lock()
+	if len(events) != 1 || events[0] != "high" {
+		t.Fatalf("events %v above the high watermark, want [high]", events)
+	}
+	mu.Unlock()
+
+	release()
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || events[1] != "low" {
+		t.Fatalf("events %v once the queue drained, want [high low]", events)
+	}
+}
@@ -1,6 +1,7 @@
 package tinyPool
 
 import (
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -106,3 +107,78 @@ func BenchmarkTinyPool_slow(b *testing.B) {
 	}
 	b.StopTimer()
 }
+
+func BenchmarkTinyPoolWithFunc_fast(b *testing.B) {
+	var wg sync.WaitGroup
+	p, _ := NewPoolWithFunc(PoolSize, func(n int) {
+		Fib(n)
+		time.Sleep(10 * time.Millisecond)
+		wg.Done()
+	})
+	defer p.Close()
+
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		wg.Add(RunTimes)
+		for j := 0; j < RunTimes; j++ {
+			_ = p.Invoke(1000)
+		}
+		wg.Wait()
+	}
+	b.StopTimer()
+}
+
+func BenchmarkInvoke(b *testing.B) {
+	var wg sync.WaitGroup
+	p, _ := NewPoolWithFunc(4, func(int) { wg.Done() }, WithDisablePurge(true), WithQueueCap(256))
+	defer p.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wg.Add(1)
+		_ = p.Invoke(i)
+	}
+	wg.Wait()
+}
+
+type benchTask struct {
+	wg *sync.WaitGroup
+}
+
+func (t *benchTask) Run() {
+	t.wg.Done()
+}
+
+func BenchmarkSubmitTask(b *testing.B) {
+	var wg sync.WaitGroup
+	p, _ := NewPool(4, WithDisablePurge(true), WithQueueCap(256))
+	defer p.Close()
+	task := &benchTask{wg: &wg}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wg.Add(1)
+		_ = p.SubmitTask(task)
+	}
+	wg.Wait()
+}
+
+func BenchmarkSubmitParallel(b *testing.B) {
+	var wg sync.WaitGroup
+	p, _ := NewPool(runtime.GOMAXPROCS(0), WithDisablePurge(true),
+		WithDispatchShards(runtime.GOMAXPROCS(0)), WithQueueCap(1024))
+	defer p.Close()
+	task := &benchTask{wg: &wg}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			wg.Add(1)
+			_ = p.SubmitTask(task)
+		}
+	})
+	wg.Wait()
+}
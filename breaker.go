@@ -0,0 +1,80 @@
+package tinyPool
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// errTaskPanicked marks a panicking task as failed for the circuit breaker.
+var errTaskPanicked = errors.New("task panicked")
+
+// circuitBreakers tracks the failures of each tag. A tag whose tasks
+// failed threshold times in a row is refused for cooldown. After that a
+// single failure opens the circuit again, a success closes it.
+type circuitBreakers struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu   sync.Mutex
+	tags map[string]*breaker
+}
+
+type breaker struct {
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreakers(threshold int, cooldown time.Duration) *circuitBreakers {
+	return &circuitBreakers{
+		threshold: threshold,
+		cooldown:  cooldown,
+		tags:      make(map[string]*breaker),
+	}
+}
+
+// allow returns ErrCircuitOpen if the circuit of tag is open.
+func (c *circuitBreakers) allow(tag string) error {
+	if c == nil || tag == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b := c.tags[tag]; b != nil && time.Now().Before(b.openUntil) {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record counts the outcome of a task of tag.
+func (c *circuitBreakers) record(tag string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err == nil {
+		delete(c.tags, tag)
+		return
+	}
+
+	b := c.tags[tag]
+	if b == nil {
+		b = &breaker{}
+		c.tags[tag] = b
+	}
+	b.failures++
+	if b.failures >= c.threshold {
+		b.openUntil = time.Now().Add(c.cooldown)
+		// half open after the cooldown, the next failure trips it again
+		b.failures = c.threshold - 1
+	}
+}
+
+// callGuarded runs a tagged task and records its outcome.
+func (p *Pool) callGuarded(j job) (err error) {
+	err = errTaskPanicked
+	defer func() {
+		p.breakers.record(j.tag, err)
+	}()
+	return j.call()
+}
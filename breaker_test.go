@@ -0,0 +1,41 @@
+package tinyPool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	p, _ := NewPool(1, WithCircuitBreaker(3, 50*time.Millisecond))
+	defer p.Close()
+
+	fail := func() error { return errors.New("downstream down") }
+	for i := 0; i < 3; i++ {
+		f := SubmitResult(p, func() (int, error) { return 0, fail() }, WithTag("db"))
+		_, _ = f.Get(context.Background())
+	}
+
+	if err := p.SubmitErr(fail, WithTag("db")); err != ErrCircuitOpen {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if p.TrySubmit(func() {}, WithTag("db")) {
+		t.Fatal("TrySubmit succeeded with the circuit open")
+	}
+	if n := p.Stats().Rejected; n != 2 {
+		t.Fatalf("%d rejected tasks, want the 2 refused by the circuit", n)
+	}
+	if err := p.Submit(func() {}, WithTag("s3")); err != nil {
+		t.Fatalf("other tag refused: %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	f := SubmitResult(p, func() (int, error) { return 1, nil }, WithTag("db"))
+	if _, err := f.Get(context.Background()); err != nil {
+		t.Fatalf("half open circuit refused: %v", err)
+	}
+	if err := p.Submit(func() {}, WithTag("db")); err != nil {
+		t.Fatalf("circuit not closed after a success: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package tinyPool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// bursting reports whether a burst window is open, see WithBurst.
+func (p *engine[T]) bursting() bool {
+	return time.Now().UnixNano() < atomic.LoadInt64(&p.burstUntil)
+}
+
+// burst starts a worker past the capacity of the saturated pool for item,
+// opening a burst window unless one is open. A window opens no sooner than
+// BurstDuration after the previous one closed. It reports false if no
+// worker was started with item.
+func (p *engine[T]) burst(item T) bool {
+	if p.Running() < p.Cap() {
+		// below capacity the scale policy decides
+		return false
+	}
+
+	d := int64(p.options.BurstDuration)
+	now := time.Now().UnixNano()
+	if until := atomic.LoadInt64(&p.burstUntil); now >= until {
+		if now < until+d || !atomic.CompareAndSwapInt64(&p.burstUntil, until, now+d) {
+			return false
+		}
+		p.log(LevelInfo, "burst started", "extra", p.options.BurstExtra, "duration", p.options.BurstDuration)
+		time.AfterFunc(p.options.BurstDuration, p.shed)
+	}
+
+	if !p.reserveWorker(p.limit()) {
+		return false
+	}
+	if p.options.WorkerInit != nil {
+		// the new worker may fail to start, item waits in the queue
+		p.startOneWorker(nil)
+		return false
+	}
+	p.startOneWorker(&item)
+	return true
+}
+
+// shed stops the idle workers past the limit once a burst window closed,
+// busy ones stop after their task.
+func (p *engine[T]) shed() {
+	p.log(LevelInfo, "burst ended", "running", p.Running())
+	for surplus := p.Running() - p.limit(); surplus > 0; surplus-- {
+		select {
+		case p.stop <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
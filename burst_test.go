@@ -0,0 +1,56 @@
+package tinyPool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithBurst(t *testing.T) {
+	p, _ := NewPool(2, WithBurst(2, 50*time.Millisecond))
+	defer p.Close()
+	p.Tune(2)
+
+	block := make(chan struct{})
+	for i := 0; i < 6; i++ {
+		_ = p.Submit(func() { <-block })
+	}
+	if n := p.Running(); n != 4 {
+		t.Fatalf("running = %d in a burst, want 4", n)
+	}
+	if n := p.Waiting(); n != 2 {
+		t.Fatalf("waiting = %d in a burst, want 2", n)
+	}
+
+	// once the window closes, the surplus stops after its task
+	time.Sleep(100 * time.Millisecond)
+	close(block)
+	time.Sleep(50 * time.Millisecond)
+	if n := p.Running(); n != 2 {
+		t.Fatalf("running = %d after the burst, want 2", n)
+	}
+}
+
+func TestBurstCooldown(t *testing.T) {
+	p, _ := NewPool(1, WithBurst(1, 50*time.Millisecond))
+	defer p.Close()
+	p.Tune(1)
+
+	first := make(chan struct{})
+	_ = p.Submit(func() { <-first })
+	_ = p.Submit(func() { <-first })
+	if n := p.Running(); n != 2 {
+		t.Fatalf("running = %d in a burst, want 2", n)
+	}
+	time.Sleep(60 * time.Millisecond)
+	close(first)
+	time.Sleep(20 * time.Millisecond)
+
+	// right after the window, the saturated pool cannot burst again
+	second := make(chan struct{})
+	defer close(second)
+	_ = p.Submit(func() { <-second })
+	_ = p.Submit(func() { <-second })
+	if n := p.Running(); n != 1 {
+		t.Fatalf("running = %d in the cooldown, want 1", n)
+	}
+}
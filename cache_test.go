@@ -0,0 +1,44 @@
+package tinyPool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithResultCache(t *testing.T) {
+	p, _ := NewPool(1, WithResultCache(10, 50*time.Millisecond))
+	defer p.Close()
+
+	var runs int32
+	fn := func() (int32, error) {
+		return atomic.AddInt32(&runs, 1), nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if n, _ := SubmitShared(p, "k", fn).Get(context.Background()); n != 1 {
+			t.Fatalf("got %d, want the cached result 1", n)
+		}
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if n, _ := SubmitShared(p, "k", fn).Get(context.Background()); n != 2 {
+		t.Fatalf("got %d after the ttl, want a second run", n)
+	}
+}
+
+func TestResultCacheEviction(t *testing.T) {
+	c := newResultCache(2, time.Minute)
+	c.put("a", 1)
+	c.put("b", 2)
+	c.get("a")
+	c.put("c", 3)
+
+	if _, ok := c.get("b"); ok {
+		t.Fatal("least recently used result not evicted")
+	}
+	if v, ok := c.get("a"); !ok || v != 1 {
+		t.Fatalf("get(a) = %v, %v", v, ok)
+	}
+}
@@ -0,0 +1,68 @@
+package tinyPool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// SubmitCancelable submits a task which gets a context that is canceled
+// by Cancel of the returned handle, or once the pool stops accepting
+// tasks, so long loops in the task can exit early. The task is dropped if
+// the context is done before it starts. A task returning the error of its
+// canceled context is reported as TaskCancelled.
+func (p *Pool) SubmitCancelable(task func(ctx context.Context) error, opts ...TaskOption) (*TaskHandle, error) {
+	ctx, cancel := context.WithCancel(p.closing)
+	h := &TaskHandle{done: make(chan struct{}), ctx: ctx, cancel: cancel, p: p}
+	return p.submitTracked(h, func() error {
+		return task(ctx)
+	}, append(opts[:len(opts):len(opts)], WithContext(ctx)))
+}
+
+// SubmitWithTimeout is like SubmitCancelable, but the context of the task
+// is also canceled once it has run for d. A task which runs out of time
+// fails with ErrTaskTimeout and is reported as TaskTimedOut, whatever it
+// returns. Tasks that ignore their context go on running, see
+// WithStuckWorkerLimit.
+func (p *Pool) SubmitWithTimeout(task func(ctx context.Context) error, d time.Duration, opts ...TaskOption) (*TaskHandle, error) {
+	ctx, cancel := context.WithCancel(p.closing)
+	h := &TaskHandle{done: make(chan struct{}), ctx: ctx, cancel: cancel, p: p}
+	return p.submitTracked(h, func() error {
+		runCtx, stop := context.WithTimeout(ctx, d)
+		defer stop()
+
+		err := task(runCtx)
+		if ctx.Err() == nil && runCtx.Err() == context.DeadlineExceeded {
+			atomic.AddInt64(&p.timedOut, 1)
+			return ErrTaskTimeout
+		}
+		return err
+	}, append(opts[:len(opts):len(opts)], WithContext(ctx)))
+}
+
+// TimedOut returns the number of tasks submitted by SubmitWithTimeout
+// which ran out of time.
+func (p *Pool) TimedOut() int64 {
+	return atomic.LoadInt64(&p.timedOut)
+}
+
+// Cancel cancels the context of a task submitted by SubmitCancelable. A
+// queued task is cancelled at once, a running one when it returns. Cancel
+// does nothing for other tasks.
+func (h *TaskHandle) Cancel() {
+	if h.cancel == nil {
+		return
+	}
+	h.cancel()
+
+	h.mu.Lock()
+	if h.status.State != TaskQueued {
+		h.mu.Unlock()
+		return
+	}
+	h.status.State = TaskCancelled
+	h.status.Finished = time.Now()
+	h.status.Err = context.Canceled
+	h.mu.Unlock()
+	h.settle()
+}
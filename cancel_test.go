@@ -0,0 +1,89 @@
+package tinyPool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubmitCancelable(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+	p.Tune(1)
+
+	running, _ := p.SubmitCancelable(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	ran := make(chan struct{})
+	queued, _ := p.SubmitCancelable(func(ctx context.Context) error {
+		close(ran)
+		return nil
+	})
+	time.Sleep(10 * time.Millisecond)
+
+	queued.Cancel()
+	if s := queued.Status(); s.State != TaskCancelled || s.Err != context.Canceled {
+		t.Fatalf("status = %+v, want cancelled", s)
+	}
+	running.Cancel()
+	if err := running.Wait(context.Background()); err != context.Canceled {
+		t.Fatalf("Wait() = %v, want canceled", err)
+	}
+	if s := running.Status(); s.State != TaskCancelled {
+		t.Fatalf("status = %+v, want cancelled", s)
+	}
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ran:
+		t.Fatal("cancelled task ran")
+	default:
+	}
+}
+
+func TestCancelableShutdown(t *testing.T) {
+	p, _ := NewPool(1)
+
+	h, _ := p.SubmitCancelable(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() = %v, want the task to exit", err)
+	}
+	if s := h.Status(); s.State != TaskDone {
+		t.Fatalf("status = %+v, want done", s)
+	}
+}
+
+func TestSubmitWithTimeout(t *testing.T) {
+	p, _ := NewPool(2)
+	defer p.Close()
+
+	slow, _ := p.SubmitWithTimeout(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, 20*time.Millisecond)
+	fast, _ := p.SubmitWithTimeout(func(ctx context.Context) error {
+		return nil
+	}, time.Second)
+
+	if err := slow.Wait(context.Background()); err != ErrTaskTimeout {
+		t.Fatalf("Wait() = %v, want ErrTaskTimeout", err)
+	}
+	if s := slow.Status(); s.State != TaskTimedOut || s.Finished.Sub(s.Started) < 20*time.Millisecond {
+		t.Fatalf("status = %+v, want timed out", s)
+	}
+	if err := fast.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+	if n := p.TimedOut(); n != 1 {
+		t.Fatalf("timed out = %d, want 1", n)
+	}
+}
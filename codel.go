@@ -0,0 +1,101 @@
+package tinyPool
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// codel is the controlled delay state of a shard, see WithCoDel. Only the
+// feeder of the shard uses it.
+type codel struct {
+	// firstAbove is when the queue wait has been above the target for a
+	// whole interval, zero while it is below
+	firstAbove time.Time
+
+	// dropping is set while tasks are dropped, dropNext is when the next
+	// one is, and count is the number of drops since dropping started
+	dropping bool
+	dropNext time.Time
+	count    int
+}
+
+// drop reports whether a task which waited for wait is to be dropped at
+// now. Once the waits stay above target for interval, a task is dropped,
+// then more at intervals shrinking with the square root of the drops,
+// until a wait is below target again.
+func (c *codel) drop(now time.Time, wait, target, interval time.Duration) bool {
+	if wait < target {
+		c.firstAbove = time.Time{}
+		c.dropping = false
+		return false
+	}
+
+	if !c.dropping {
+		if c.firstAbove.IsZero() {
+			c.firstAbove = now.Add(interval)
+			return false
+		}
+		if now.Before(c.firstAbove) {
+			return false
+		}
+		c.dropping = true
+		// soon after the last dropping, the queue still needs about as
+		// many drops
+		if c.count > 2 && now.Sub(c.dropNext) < 8*interval {
+			c.count -= 2
+		} else {
+			c.count = 1
+		}
+		c.dropNext = codelNext(now, interval, c.count)
+		return true
+	}
+
+	if now.Before(c.dropNext) {
+		return false
+	}
+	c.count++
+	c.dropNext = codelNext(c.dropNext, interval, c.count)
+	return true
+}
+
+// codelNext returns when to drop the next task after count drops.
+func codelNext(t time.Time, interval time.Duration, count int) time.Time {
+	return t.Add(time.Duration(float64(interval) / math.Sqrt(float64(count))))
+}
+
+// overdue reports whether item, just popped from the queue of s, is to be
+// dropped by CoDel, and drops it.
+func (p *engine[T]) overdue(s *shard[T], item T) bool {
+	target := p.options.CoDelTarget
+	if target <= 0 || p.submittedAt == nil {
+		return false
+	}
+	now := time.Now()
+	wait := now.Sub(p.submittedAt(item))
+	if !s.codel.drop(now, wait, target, p.options.CoDelInterval) {
+		return false
+	}
+	p.drop(item, wait)
+	return true
+}
+
+// drop drops item, which was queued for wait, with ErrTaskDropped.
+func (p *engine[T]) drop(item T, wait time.Duration) {
+	atomic.AddInt64(&p.dropped, 1)
+	atomic.AddInt64(&p.pending, -1)
+	p.log(LevelWarn, "task dropped", "task", p.name(item), "wait", wait)
+	p.traced(item).end(ErrTaskDropped)
+	if h := p.options.Hooks.OnDrop; h != nil {
+		h(p.name(item), wait)
+	}
+	if p.abort != nil {
+		p.abort(item, ErrTaskDropped)
+	}
+}
+
+// Dropped returns the number of tasks dropped by CoDel, DiscardOldestPolicy
+// or DiscardNewestPolicy.
+func (p *engine[T]) Dropped() int64 {
+	return atomic.LoadInt64(&p.dropped)
+}
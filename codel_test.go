@@ -0,0 +1,72 @@
+package tinyPool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCodelDrop(t *testing.T) {
+	var c codel
+	target, interval := 10*time.Millisecond, 100*time.Millisecond
+	now := time.Now()
+
+	if c.drop(now, 20*time.Millisecond, target, interval) {
+		t.Fatal("dropped at the first wait above the target")
+	}
+	if c.drop(now.Add(50*time.Millisecond), 20*time.Millisecond, target, interval) {
+		t.Fatal("dropped within the interval")
+	}
+	now = now.Add(interval)
+	if !c.drop(now, 20*time.Millisecond, target, interval) {
+		t.Fatal("not dropped after a whole interval above the target")
+	}
+	if c.drop(now.Add(time.Millisecond), 20*time.Millisecond, target, interval) {
+		t.Fatal("dropped right after the last drop")
+	}
+
+	// the next drops come at interval/sqrt(count)
+	now = now.Add(interval)
+	if !c.drop(now, 20*time.Millisecond, target, interval) {
+		t.Fatal("not dropped an interval after the first drop")
+	}
+	if want := now.Add(time.Duration(float64(interval) / 1.4142135623730951)); !c.dropNext.Equal(want) {
+		t.Fatalf("next drop at %v, want %v", c.dropNext.Sub(now), want.Sub(now))
+	}
+
+	// a wait below the target stops dropping
+	if c.drop(now.Add(interval), time.Millisecond, target, interval) || c.dropping {
+		t.Fatal("still dropping below the target")
+	}
+}
+
+func TestWithCoDel(t *testing.T) {
+	var dropped int64
+	p, _ := NewPool(1, WithCoDel(5*time.Millisecond, 20*time.Millisecond), WithHooks(Hooks{
+		OnDrop: func(name string, wait time.Duration) { atomic.AddInt64(&dropped, 1) },
+	}))
+	defer p.Close()
+	p.Tune(1)
+
+	var futures []*Future[int]
+	for i := 0; i < 40; i++ {
+		futures = append(futures, SubmitResult(p, func() (int, error) {
+			time.Sleep(5 * time.Millisecond)
+			return 0, nil
+		}))
+	}
+	var failed int64
+	for _, f := range futures {
+		if _, err := f.Get(context.Background()); err == ErrTaskDropped {
+			failed++
+		}
+	}
+
+	if n := p.Dropped(); n == 0 || n != atomic.LoadInt64(&dropped) || n != failed {
+		t.Fatalf("dropped %d, OnDrop called %d times, %d futures failed", n, dropped, failed)
+	}
+	if s := p.Stats(); s.Dropped != failed {
+		t.Fatalf("stats tell %d dropped, want %d", s.Dropped, failed)
+	}
+}
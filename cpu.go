@@ -0,0 +1,149 @@
+package tinyPool
+
+import (
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// procsInterval is how often a pool checks whether GOMAXPROCS changed.
+const procsInterval = time.Second
+
+// hostQuota is the CPU quota of the cgroup of the process, read once.
+var hostQuota = sync.OnceValue(func() float64 {
+	return cpuQuota(os.DirFS("/"))
+})
+
+// defaultSize returns the number of CPUs the process may use, the default
+// capacity of a pool: GOMAXPROCS, lowered to the CPU quota of its cgroup
+// rounded up, so a pool in a container doesn't oversubscribe its limit.
+func defaultSize() int {
+	n := runtime.GOMAXPROCS(0)
+	if q := hostQuota(); q > 0 {
+		if c := int(math.Ceil(q)); c < n {
+			n = c
+		}
+	}
+	return n
+}
+
+// Resize derives the capacity of the pool again from GOMAXPROCS and the
+// cgroup CPU quota, like NewPool, for when the runtime was retuned. The
+// pool checks GOMAXPROCS every second on its own, Resize applies a change
+// right away. Pools which were tuned, or are autoscaled, keep their
+// capacity.
+func (p *engine[T]) Resize() {
+	if atomic.LoadInt32(&p.followProcs) == 0 {
+		return
+	}
+	size := int32(defaultSize())
+	if size < p.size {
+		size = p.size
+	}
+	if size != p.Cap() {
+		p.log(LevelInfo, "resized to the CPUs", "size", size, "GOMAXPROCS", runtime.GOMAXPROCS(0))
+		p.tune(int(size))
+	}
+}
+
+// cpuQuota returns the number of CPUs the cgroup of the process may use
+// per period, read from the cgroup v2 or v1 files under fsys, 0 if it has
+// no quota. The cgroup paths in /proc/self/cgroup are relative to the root
+// of the host hierarchy, which a container often has mounted as its root,
+// so the root of the mount is tried as well.
+func cpuQuota(fsys fs.FS) float64 {
+	data, err := fs.ReadFile(fsys, "proc/self/cgroup")
+	if err != nil {
+		return 0
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		// hierarchy-ID:controllers:path
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		group := strings.TrimPrefix(fields[2], "/")
+
+		if fields[0] == "0" && fields[1] == "" {
+			for _, mount := range []string{"sys/fs/cgroup", "sys/fs/cgroup/unified"} {
+				for _, dir := range []string{path.Join(mount, group), mount} {
+					if q, ok := cpuMax(fsys, dir); ok {
+						return q
+					}
+				}
+			}
+			continue
+		}
+
+		for _, controller := range strings.Split(fields[1], ",") {
+			if controller != "cpu" {
+				continue
+			}
+			for _, mount := range []string{"sys/fs/cgroup/cpu", "sys/fs/cgroup/cpu,cpuacct"} {
+				for _, dir := range []string{path.Join(mount, group), mount} {
+					if q, ok := cfsQuota(fsys, dir); ok {
+						return q
+					}
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// cpuMax reads the cgroup v2 quota in dir, "max" or a quota and a period
+// in microseconds. It reports false if dir has no cpu.max.
+func cpuMax(fsys fs.FS, dir string) (float64, bool) {
+	data, err := fs.ReadFile(fsys, path.Join(dir, "cpu.max"))
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || fields[0] == "max" {
+		return 0, true
+	}
+	period := 100000.0
+	if len(fields) > 1 {
+		if p, err := strconv.ParseFloat(fields[1], 64); err == nil && p > 0 {
+			period = p
+		}
+	}
+	quota, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || quota <= 0 {
+		return 0, true
+	}
+	return quota / period, true
+}
+
+// cfsQuota reads the cgroup v1 quota and period in dir, a quota of -1
+// meaning none. It reports false if dir has no quota.
+func cfsQuota(fsys fs.FS, dir string) (float64, bool) {
+	quota, err := readCgroupInt(fsys, path.Join(dir, "cpu.cfs_quota_us"))
+	if err != nil {
+		return 0, false
+	}
+	if quota <= 0 {
+		return 0, true
+	}
+	period, err := readCgroupInt(fsys, path.Join(dir, "cpu.cfs_period_us"))
+	if err != nil || period <= 0 {
+		return 0, true
+	}
+	return float64(quota) / float64(period), true
+}
+
+func readCgroupInt(fsys fs.FS, name string) (int64, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
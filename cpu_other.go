@@ -0,0 +1,11 @@
+//go:build !unix
+
+package tinyPool
+
+import "time"
+
+// processCPUTime returns the user and system CPU time the process used so
+// far. It reports false if it is unknown.
+func processCPUTime() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,96 @@
+package tinyPool
+
+import (
+	"runtime"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCPUQuota(t *testing.T) {
+	file := func(data string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(data)} }
+
+	cases := []struct {
+		name string
+		fsys fstest.MapFS
+		want float64
+	}{
+		{"none", fstest.MapFS{}, 0},
+		{"v2", fstest.MapFS{
+			"proc/self/cgroup":                  file("0::/app\n"),
+			"sys/fs/cgroup/app/cpu.max":         file("150000 100000\n"),
+			"sys/fs/cgroup/cpu.max":             file("max 100000\n"),
+			"sys/fs/cgroup/unified/app/cpu.max": file("400000 100000\n"),
+		}, 1.5},
+		{"v2 unlimited", fstest.MapFS{
+			"proc/self/cgroup":      file("0::/\n"),
+			"sys/fs/cgroup/cpu.max": file("max 100000\n"),
+		}, 0},
+		{"v2 mounted at the root", fstest.MapFS{
+			"proc/self/cgroup":      file("0::/kubepods/pod1\n"),
+			"sys/fs/cgroup/cpu.max": file("50000 100000\n"),
+		}, 0.5},
+		{"v1", fstest.MapFS{
+			"proc/self/cgroup":                            file("4:memory:/docker/c1\n2:cpu,cpuacct:/docker/c1\n"),
+			"sys/fs/cgroup/cpu,cpuacct/cpu.cfs_quota_us":  file("300000\n"),
+			"sys/fs/cgroup/cpu,cpuacct/cpu.cfs_period_us": file("100000\n"),
+		}, 3},
+		{"v1 unlimited", fstest.MapFS{
+			"proc/self/cgroup":                    file("1:cpu:/\n"),
+			"sys/fs/cgroup/cpu/cpu.cfs_quota_us":  file("-1\n"),
+			"sys/fs/cgroup/cpu/cpu.cfs_period_us": file("100000\n"),
+		}, 0},
+	}
+	for _, c := range cases {
+		if got := cpuQuota(c.fsys); got != c.want {
+			t.Errorf("%s: quota = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDefaultSize(t *testing.T) {
+	n := defaultSize()
+	if n < 1 || n > runtime.GOMAXPROCS(0) {
+		t.Fatalf("default size = %d, want 1 to GOMAXPROCS", n)
+	}
+
+	p, _ := NewPool(0)
+	defer p.Close()
+	if c := p.Cap(); int(c) != n {
+		t.Fatalf("cap = %d with size 0, want %d", c, n)
+	}
+}
+
+func TestResize(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	if defaultSize() != 4 {
+		t.Skip("the CPU quota is below 4")
+	}
+
+	p, _ := NewPool(0)
+	defer p.Close()
+	if c := p.Cap(); c != 4 {
+		t.Fatalf("cap = %d with GOMAXPROCS 4, want 4", c)
+	}
+
+	runtime.GOMAXPROCS(2)
+	p.Resize()
+	if c := p.Cap(); c != 2 {
+		t.Fatalf("cap = %d after GOMAXPROCS 2, want 2", c)
+	}
+
+	// a pool of an explicit size keeps it
+	q, _ := NewPool(3)
+	defer q.Close()
+	q.Resize()
+	if c := q.Cap(); c != 3 {
+		t.Fatalf("cap = %d of a pool of size 3, want 3", c)
+	}
+
+	// so does a tuned one
+	p.Tune(3)
+	runtime.GOMAXPROCS(4)
+	p.Resize()
+	if c := p.Cap(); c != 3 {
+		t.Fatalf("cap = %d of a tuned pool, want 3", c)
+	}
+}
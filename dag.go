@@ -0,0 +1,146 @@
+package tinyPool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DAG is a set of tasks with dependencies, run on a pool with as much
+// parallelism as the dependencies allow.
+type DAG struct {
+	p     *Pool
+	nodes map[string]*dagNode
+
+	// order keeps the nodes in the order they were added
+	order []string
+}
+
+type dagNode struct {
+	name       string
+	fn         func(ctx context.Context) error
+	deps       []string
+	dependents []*dagNode
+	waiting    int
+}
+
+// DAG returns an empty DAG running on p.
+func (p *Pool) DAG() *DAG {
+	return &DAG{p: p, nodes: make(map[string]*dagNode)}
+}
+
+// Add adds the task name, which runs after all tasks in deps succeeded.
+// Dependencies may be added after their dependents.
+func (d *DAG) Add(name string, fn func(ctx context.Context) error, deps ...string) error {
+	if _, ok := d.nodes[name]; ok {
+		return fmt.Errorf("dag: duplicate task %q", name)
+	}
+
+	d.nodes[name] = &dagNode{name: name, fn: fn, deps: deps}
+	d.order = append(d.order, name)
+	return nil
+}
+
+// Run runs the tasks of d and returns the names of the tasks which
+// succeeded, in completion order. The first failure cancels the context
+// of the running tasks, no further task is started and its error is
+// returned. Run fails without running anything if a dependency is
+// unknown or the dependencies form a cycle.
+func (d *DAG) Run(ctx context.Context) ([]string, error) {
+	roots, err := d.link()
+	if err != nil {
+		return nil, err
+	}
+
+	g := d.p.Group(ctx)
+	var mu sync.Mutex
+	var completed []string
+
+	var start func(n *dagNode)
+	start = func(n *dagNode) {
+		g.Go(func() error {
+			if g.ctx.Err() != nil {
+				return nil
+			}
+			if err := n.fn(g.ctx); err != nil {
+				return fmt.Errorf("dag: task %q: %w", n.name, err)
+			}
+
+			mu.Lock()
+			completed = append(completed, n.name)
+			var ready []*dagNode
+			for _, dep := range n.dependents {
+				dep.waiting--
+				if dep.waiting == 0 {
+					ready = append(ready, dep)
+				}
+			}
+			mu.Unlock()
+
+			for _, r := range ready {
+				if g.ctx.Err() == nil {
+					start(r)
+				}
+			}
+			return nil
+		})
+	}
+	for _, n := range roots {
+		start(n)
+	}
+
+	err = g.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	return completed, err
+}
+
+// link resolves the dependencies of the nodes and returns the nodes
+// without any. It fails on unknown dependencies and cycles.
+func (d *DAG) link() ([]*dagNode, error) {
+	for _, name := range d.order {
+		n := d.nodes[name]
+		n.dependents = nil
+	}
+
+	var roots []*dagNode
+	for _, name := range d.order {
+		n := d.nodes[name]
+		n.waiting = len(n.deps)
+		if n.waiting == 0 {
+			roots = append(roots, n)
+		}
+		for _, dep := range n.deps {
+			parent, ok := d.nodes[dep]
+			if !ok {
+				return nil, fmt.Errorf("dag: task %q depends on unknown task %q", name, dep)
+			}
+			parent.dependents = append(parent.dependents, n)
+		}
+	}
+
+	// Kahn's algorithm visits every node unless there is a cycle
+	waiting := make(map[*dagNode]int, len(d.nodes))
+	queue := append([]*dagNode(nil), roots...)
+	visited := 0
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, dep := range n.dependents {
+			if _, ok := waiting[dep]; !ok {
+				waiting[dep] = dep.waiting
+			}
+			waiting[dep]--
+			if waiting[dep] == 0 {
+				queue = append(queue, dep)
+			}
+		}
+	}
+	if visited != len(d.nodes) {
+		return nil, fmt.Errorf("dag: dependency cycle")
+	}
+
+	return roots, nil
+}
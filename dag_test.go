@@ -0,0 +1,80 @@
+package tinyPool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestDAG(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	var mu sync.Mutex
+	var order []string
+	step := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	d := p.DAG()
+	_ = d.Add("deploy", step("deploy"), "build", "test")
+	_ = d.Add("build", step("build"), "fetch")
+	_ = d.Add("test", step("test"), "fetch")
+	_ = d.Add("fetch", step("fetch"))
+
+	completed, err := d.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(completed) != 4 || order[0] != "fetch" || order[3] != "deploy" {
+		t.Fatalf("ran %v, completed %v", order, completed)
+	}
+}
+
+func TestDAGFailFast(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	boom := errors.New("boom")
+	ran := false
+	d := p.DAG()
+	_ = d.Add("a", func(context.Context) error { return nil })
+	_ = d.Add("b", func(context.Context) error { return boom }, "a")
+	_ = d.Add("c", func(context.Context) error { ran = true; return nil }, "b")
+
+	completed, err := d.Run(context.Background())
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	if ran || len(completed) != 1 || completed[0] != "a" {
+		t.Fatalf("completed %v, ran dependent of failed task: %v", completed, ran)
+	}
+}
+
+func TestDAGInvalid(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	nop := func(context.Context) error { return nil }
+	d := p.DAG()
+	_ = d.Add("a", nop, "b")
+	_ = d.Add("b", nop, "a")
+	if _, err := d.Run(context.Background()); err == nil {
+		t.Fatal("cycle not detected")
+	}
+
+	d = p.DAG()
+	_ = d.Add("a", nop, "missing")
+	if _, err := d.Run(context.Background()); err == nil {
+		t.Fatal("unknown dependency not detected")
+	}
+	if err := d.Add("a", nop); err == nil {
+		t.Fatal("duplicate task not detected")
+	}
+}
@@ -0,0 +1,130 @@
+package tinyPool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// WithDeadline sets a deadline for the task to start. If it is still
+// queued when the deadline passes, it is dropped with ErrTaskExpired
+// instead of running stale.
+func WithDeadline(deadline time.Time) TaskOption {
+	return func(j *job) {
+		j.deadline = deadline
+	}
+}
+
+// WithContext drops the task with ctx.Err() if ctx is done before the task
+// starts. The task itself doesn't see ctx, see SubmitWithContext.
+func WithContext(ctx context.Context) TaskOption {
+	return func(j *job) {
+		j.ctx = ctx
+	}
+}
+
+// Expired returns the number of tasks dropped because their deadline
+// passed while they were queued.
+func (p *Pool) Expired() int64 {
+	return atomic.LoadInt64(&p.expired)
+}
+
+// stale reports whether the deadline for item to start has passed.
+func (p *engine[T]) stale(item T) bool {
+	if p.deadlineOf == nil {
+		return false
+	}
+	deadline := p.deadlineOf(item)
+	return !deadline.IsZero() && time.Now().After(deadline)
+}
+
+// dropStale drops item with ErrTaskExpired if its deadline has passed,
+// before it is handed to a worker.
+func (p *engine[T]) dropStale(item T) bool {
+	if !p.stale(item) {
+		return false
+	}
+	atomic.AddInt64(&p.pending, -1)
+	p.expire(item)
+	return true
+}
+
+// expire drops item, taken out of the queue, with ErrTaskExpired.
+func (p *engine[T]) expire(item T) {
+	atomic.AddInt64(&p.expired, 1)
+	p.log(LevelWarn, "task expired", "task", p.name(item))
+	p.traced(item).end(ErrTaskExpired)
+	if p.abort != nil {
+		p.abort(item, ErrTaskExpired)
+	}
+}
+
+// expiry returns a channel which fires at the deadline of item, which the
+// feeder of s is handing out, nil if item has no deadline.
+func (p *engine[T]) expiry(s *shard[T], item T) <-chan time.Time {
+	if p.deadlineOf == nil {
+		return nil
+	}
+	deadline := p.deadlineOf(item)
+	if deadline.IsZero() {
+		return nil
+	}
+	if s.deadline == nil {
+		s.deadline = time.NewTimer(time.Until(deadline))
+		return s.deadline.C
+	}
+	if !s.deadline.Stop() {
+		select {
+		case <-s.deadline.C:
+		default:
+		}
+	}
+	s.deadline.Reset(time.Until(deadline))
+	return s.deadline.C
+}
+
+// call runs j unless its context is done. A failed task with retries left
+// is scheduled to run again.
+func (p *Pool) call(j job) error {
+	if j.ctx != nil && j.ctx.Err() != nil {
+		if j.abort != nil {
+			j.abort(j.ctx.Err())
+		}
+		return j.ctx.Err()
+	}
+
+	err := p.invoke(j)
+	if err != nil {
+		if p.retry(j, err) {
+			return nil
+		}
+		p.deadLetter(j, j.attempt+1, err)
+	}
+	if j.finish != nil {
+		j.finish()
+	}
+	return err
+}
+
+// invoke runs j through its interceptor and circuit breaker.
+func (p *Pool) invoke(j job) error {
+	if j.intercept == nil && (p.breakers == nil || j.tag == "") {
+		return j.call()
+	}
+	return p.invokeWrapped(j)
+}
+
+// invokeWrapped is invoke for a task with an interceptor or a circuit
+// breaker. Split from invoke, so only such tasks escape to the heap.
+func (p *Pool) invokeWrapped(j job) error {
+	run := j.call
+	if p.breakers != nil && j.tag != "" {
+		run = func() error {
+			return p.callGuarded(j)
+		}
+	}
+	if j.intercept != nil {
+		return j.intercept(run)
+	}
+	return run()
+}
@@ -0,0 +1,66 @@
+package tinyPool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithDeadline(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	release := saturate(p)
+
+	var ran int32
+	deadline := time.Now().Add(10 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		_ = p.Submit(func() {
+			atomic.AddInt32(&ran, 1)
+		}, WithDeadline(deadline))
+	}
+	f := SubmitResult(p, func() (int, error) {
+		return 1, nil
+	}, WithDeadline(deadline))
+
+	time.Sleep(20 * time.Millisecond)
+	release()
+
+	if _, err := f.Get(context.Background()); err != ErrTaskExpired {
+		t.Fatalf("Get() err = %v, want ErrTaskExpired", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := atomic.LoadInt32(&ran); n != 0 {
+		t.Fatalf("%d expired tasks ran", n)
+	}
+	if n := p.Expired(); n != 6 {
+		t.Fatalf("Expired() = %d, want 6", n)
+	}
+}
+
+func TestWithDeadlineWorkersBusy(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+	p.Tune(1)
+
+	release := saturate(p)
+	defer release()
+
+	f := SubmitResult(p, func() (int, error) {
+		return 1, nil
+	}, WithDeadline(time.Now().Add(10*time.Millisecond)))
+
+	// the task expires in the queue, without waiting for a worker
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := f.Get(ctx); err != ErrTaskExpired {
+		t.Fatalf("Get() err = %v, want ErrTaskExpired", err)
+	}
+	if n := p.Expired(); n != 1 {
+		t.Fatalf("Expired() = %d, want 1", n)
+	}
+	if n := p.Stats().Completed; n != 0 {
+		t.Fatalf("%d tasks completed, want the expired one not run by a worker", n)
+	}
+}
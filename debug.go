@@ -0,0 +1,185 @@
+package tinyPool
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recentLatencies is the number of task durations shown by DebugHandler.
+const recentLatencies = 64
+
+// debugState is the pool state rendered by DebugHandler.
+type debugState struct {
+	Stats     Stats             `json:"stats"`
+	Workers   []WorkerInfo      `json:"workers"`
+	Tasks     []TaskStatus      `json:"tasks"`
+	Durations DurationHistogram `json:"durations"`
+	Recent    []time.Duration   `json:"recent_latencies"`
+	Config    map[string]string `json:"config"`
+}
+
+// recentDurations keeps the latest task durations, observed from the
+// first call of DebugHandler on.
+type recentDurations struct {
+	once sync.Once
+
+	mu        sync.Mutex
+	durations [recentLatencies]time.Duration
+	n         int
+}
+
+func (r *recentDurations) add(d time.Duration) {
+	r.mu.Lock()
+	r.durations[r.n%recentLatencies] = d
+	r.n++
+	r.mu.Unlock()
+}
+
+// newest returns the kept durations, newest first.
+func (r *recentDurations) newest() []time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var recent []time.Duration
+	for i := 0; i < r.n && i < recentLatencies; i++ {
+		recent = append(recent, r.durations[(r.n-1-i)%recentLatencies])
+	}
+	return recent
+}
+
+// DebugHandler returns an http.Handler rendering the live state of the
+// pool: its stats, the tasks its workers run, the tracked tasks and their
+// progress, the latencies of the latest tasks, the duration histogram and
+// its configuration. It serves JSON if the request asks for it with
+// ?format=json or an Accept header, HTML otherwise. Mount it at e.g.
+// /debug/tinypool.
+func (p *engine[T]) DebugHandler() http.Handler {
+	p.recent.once.Do(func() {
+		p.ObserveDurations(p.recent.add)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := debugState{
+			Stats:     p.Stats(),
+			Workers:   p.DumpWorkers(),
+			Durations: p.Durations(),
+			Recent:    p.recent.newest(),
+			Config:    p.options.describe(),
+		}
+		if p.activeTasks != nil {
+			state.Tasks = p.activeTasks()
+		}
+
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(state)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = debugPage.Execute(w, state)
+	})
+}
+
+// describe returns the settings of opts for display.
+func (opts *Options) describe() map[string]string {
+	config := map[string]string{
+		"Name":                  opts.Name,
+		"ExpiryDuration":        opts.ExpiryDuration.String(),
+		"DisablePurge":          fmt.Sprint(opts.DisablePurge),
+		"ScaleToZero":           fmt.Sprint(opts.ScaleToZero),
+		"EarliestDeadlineFirst": fmt.Sprint(opts.EarliestDeadlineFirst),
+		"FairQueueing":          fmt.Sprint(opts.FairQueueing),
+		"PreAlloc":              fmt.Sprint(opts.PreAlloc),
+		"MinWorkers":            fmt.Sprint(opts.MinWorkers),
+		"CoreSize":              fmt.Sprintf("%d, grow past %d queued", opts.CoreSize, opts.GrowThreshold),
+		"QueueCap":              fmt.Sprint(opts.QueueCap),
+		"Nonblocking":           fmt.Sprint(opts.Nonblocking),
+		"MaxBlockingTasks":      fmt.Sprint(opts.MaxBlockingTasks),
+		"RejectionPolicy":       fmt.Sprintf("%T", opts.RejectionPolicy),
+		"ScalePolicy":           fmt.Sprintf("%T", opts.ScalePolicy),
+		"Burst":                 fmt.Sprintf("%d for %v", opts.BurstExtra, opts.BurstDuration),
+		"Autoscale":             fmt.Sprintf("%d to %d every %v, wait %v", opts.AutoscaleMin, opts.AutoscaleMax, opts.AutoscaleInterval, opts.AutoscaleTargetWait),
+		"KeyShards":             fmt.Sprint(opts.KeyShards),
+		"DispatchShards":        fmt.Sprint(opts.DispatchShards),
+		"WorkStealing":          fmt.Sprint(opts.WorkStealing),
+	}
+	if opts.RateLimit > 0 {
+		config["RateLimit"] = fmt.Sprintf("%g/s burst %d", float64(opts.RateLimit), opts.RateBurst)
+	}
+	if opts.ConcurrencyLimit != nil {
+		config["ConcurrencyLimit"] = fmt.Sprintf("%T, reject %v", opts.ConcurrencyLimit, opts.RejectOverLimit)
+	}
+	if opts.CoDelTarget > 0 {
+		config["CoDel"] = fmt.Sprintf("target %v, interval %v", opts.CoDelTarget, opts.CoDelInterval)
+	}
+	if opts.BacklogHigh > 0 {
+		config["BacklogWatermarks"] = fmt.Sprintf("high %d, low %d", opts.BacklogHigh, opts.BacklogLow)
+	}
+	if opts.SLO > 0 {
+		config["SLO"] = fmt.Sprintf("%v, %T", opts.SLO, opts.SLOEstimator)
+	}
+	if opts.CPUThreshold > 0 {
+		config["CPUGovernor"] = fmt.Sprintf("%g every %v", opts.CPUThreshold, opts.CPUInterval)
+	}
+	if opts.MemoryWatermark > 0 {
+		config["MemoryWatermark"] = fmt.Sprintf("%d bytes, reject %v", opts.MemoryWatermark, opts.RejectOverMemory)
+	}
+	if opts.StuckWorkerLimit > 0 {
+		config["StuckWorkerLimit"] = fmt.Sprintf("%v, replace %v", opts.StuckWorkerLimit, opts.ReplaceStuckWorkers)
+	}
+	if opts.BreakerThreshold > 0 {
+		config["CircuitBreaker"] = fmt.Sprintf("%d failures, %v cooldown", opts.BreakerThreshold, opts.BreakerCooldown)
+	}
+	return config
+}
+
+var debugPage = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head><title>tinyPool</title></head>
+<body>
+<h1>tinyPool</h1>
+<h2>Workers</h2>
+<table>
+<tr><td>Capacity</td><td>{{.Stats.Cap}}</td></tr>
+<tr><td>Running</td><td>{{.Stats.Running}}</td></tr>
+<tr><td>Idle</td><td>{{.Stats.Idle}}</td></tr>
+<tr><td>Free</td><td>{{.Stats.Free}}</td></tr>
+<tr><td>Stuck</td><td>{{.Stats.Stuck}}</td></tr>
+</table>
+<h2>Busy workers</h2>
+<table>
+{{range .Workers}}{{if .Busy}}<tr><td>{{.ID}}</td><td>{{.Task}}</td><td>{{.Tag}}</td><td>{{.Started.Format "15:04:05.000"}}</td></tr>
+{{end}}{{end}}</table>
+<h2>Tracked tasks</h2>
+<table>
+{{range .Tasks}}<tr><td>{{.ID}}</td><td>{{.Name}}</td><td>{{.State}}</td><td><progress value="{{.Progress}}" max="1"></progress></td></tr>
+{{end}}</table>
+<h2>Tasks</h2>
+<table>
+<tr><td>Queued</td><td>{{.Stats.Waiting}}</td></tr>
+<tr><td>Submitted</td><td>{{.Stats.Submitted}}</td></tr>
+<tr><td>Completed</td><td>{{.Stats.Completed}}</td></tr>
+<tr><td>Rejected</td><td>{{.Stats.Rejected}}</td></tr>
+<tr><td>Dropped</td><td>{{.Stats.Dropped}}</td></tr>
+<tr><td>p50 / p95 / p99</td><td>{{.Stats.P50}} / {{.Stats.P95}} / {{.Stats.P99}}</td></tr>
+<tr><td>Queue wait p50 / p95 / p99</td><td>{{.Stats.WaitP50}} / {{.Stats.WaitP95}} / {{.Stats.WaitP99}}</td></tr>
+</table>
+<h2>Recent latencies</h2>
+<p>{{range .Recent}}{{.}} {{else}}none{{end}}</p>
+<h2>Duration histogram</h2>
+<table>
+{{$counts := .Durations.Counts}}{{$last := 0}}{{range $i, $b := .Durations.Bounds}}<tr><td>&le; {{$b}}</td><td>{{index $counts $i}}</td></tr>
+{{$last = $b}}{{end}}<tr><td>&gt; {{$last}}</td><td>{{index $counts (len .Durations.Bounds)}}</td></tr>
+</table>
+<h2>Config</h2>
+<table>
+{{range $k, $v := .Config}}<tr><td>{{$k}}</td><td>{{$v}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,48 @@
+package tinyPool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugHandler(t *testing.T) {
+	p, _ := NewPool(4, WithQueueCap(8))
+	h := p.DebugHandler()
+	_ = p.Submit(func() {})
+	p.durations.add(time.Hour)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/tinypool?format=json", nil))
+	var state debugState
+	if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Stats.Completed != 1 || len(state.Recent) != 1 || state.Config["QueueCap"] != "8" {
+		t.Fatalf("state = %+v", state)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/tinypool", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "<h1>tinyPool</h1>") ||
+		!strings.Contains(body, "<tr><td>&gt; 13.1072s</td><td>1</td></tr>") {
+		t.Fatalf("html page = %s", body)
+	}
+}
+
+func TestDebugHandlerObservesOnce(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	p.DebugHandler()
+	p.DebugHandler()
+	if n := len(*p.observers.Load()); n != 1 {
+		t.Fatalf("%d duration observers after 2 handlers, want 1", n)
+	}
+}
@@ -0,0 +1,192 @@
+package tinyPool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// deque holds the tasks handed to a busy worker, see WithWorkStealing. The
+// worker takes its tasks in submission order from the top, while idle peers
+// steal the newest ones from the bottom, so they rarely meet.
+type deque[T any] struct {
+	mu sync.Mutex
+
+	// items is a ring of n tasks starting at head
+	items []T
+	head  int
+	n     int
+
+	// size is n, readable without the lock
+	size int64
+
+	// dead is set when the worker has exited, so pushes go elsewhere
+	dead bool
+
+	// wake wakes the worker up after a push
+	wake chan struct{}
+}
+
+func newDeque[T any]() *deque[T] {
+	return &deque[T]{items: make([]T, 8), wake: make(chan struct{}, 1)}
+}
+
+// push puts item at the bottom. It reports false if the worker has exited.
+func (d *deque[T]) push(item T) bool {
+	d.mu.Lock()
+	if d.dead {
+		d.mu.Unlock()
+		return false
+	}
+	if d.n == len(d.items) {
+		items := make([]T, 2*len(d.items))
+		for i := 0; i < d.n; i++ {
+			items[i] = d.items[(d.head+i)%len(d.items)]
+		}
+		d.items, d.head = items, 0
+	}
+	d.items[(d.head+d.n)%len(d.items)] = item
+	d.n++
+	atomic.StoreInt64(&d.size, int64(d.n))
+	d.mu.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// pop removes the oldest task, which the worker runs next.
+func (d *deque[T]) pop() (item T, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.n == 0 {
+		return item, false
+	}
+	item, d.items[d.head] = d.items[d.head], item
+	d.head = (d.head + 1) % len(d.items)
+	d.n--
+	atomic.StoreInt64(&d.size, int64(d.n))
+	return item, true
+}
+
+// steal removes the newest task, the last one the worker would run.
+func (d *deque[T]) steal() (item T, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.n == 0 {
+		return item, false
+	}
+	d.n--
+	i := (d.head + d.n) % len(d.items)
+	item, d.items[i] = d.items[i], item
+	atomic.StoreInt64(&d.size, int64(d.n))
+	return item, true
+}
+
+// close refuses further pushes and returns the tasks left, oldest first.
+func (d *deque[T]) close() []T {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.dead = true
+	items := make([]T, d.n)
+	for i := range items {
+		items[i] = d.items[(d.head+i)%len(d.items)]
+	}
+	d.items, d.head, d.n = nil, 0, 0
+	atomic.StoreInt64(&d.size, 0)
+	return items
+}
+
+// addDeque registers the deque of a starting worker.
+func (p *engine[T]) addDeque() *deque[T] {
+	d := newDeque[T]()
+
+	p.dequesMu.Lock()
+	defer p.dequesMu.Unlock()
+	var deques []*deque[T]
+	if old := p.deques.Load(); old != nil {
+		deques = append(deques, *old...)
+	}
+	deques = append(deques, d)
+	p.deques.Store(&deques)
+	return d
+}
+
+// removeDeque unregisters the deque of an exiting worker and queues its
+// tasks for the others.
+func (p *engine[T]) removeDeque(d *deque[T]) {
+	p.dequesMu.Lock()
+	var deques []*deque[T]
+	for _, other := range *p.deques.Load() {
+		if other != d {
+			deques = append(deques, other)
+		}
+	}
+	p.deques.Store(&deques)
+	p.dequesMu.Unlock()
+
+	for _, item := range d.close() {
+		p.pushShard(item)
+	}
+}
+
+// pushLocal hands item to the deque of one of the workers and wakes an
+// idle worker up to steal it. It reports false if there is no worker.
+func (p *engine[T]) pushLocal(item T) bool {
+	deques := p.deques.Load()
+	if deques == nil || len(*deques) == 0 {
+		return false
+	}
+	d := (*deques)[atomic.AddUint32(&p.nextPush, 1)%uint32(len(*deques))]
+	if !d.push(item) {
+		return false
+	}
+
+	if atomic.LoadInt32(&p.idle) > 0 {
+		select {
+		case p.stealSig <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// local returns the next task of the worker owning d from d, or stolen
+// from the deque of a peer.
+func (p *engine[T]) local(d *deque[T]) (item T, ok bool) {
+	if item, ok = d.pop(); ok {
+		p.backlog()
+		return item, true
+	}
+
+	deques := *p.deques.Load()
+	start := int(atomic.AddUint32(&p.nextVictim, 1))
+	for i := range deques {
+		victim := deques[(start+i)%len(deques)]
+		if victim == d || atomic.LoadInt64(&victim.size) == 0 {
+			continue
+		}
+		if item, ok = victim.steal(); ok {
+			p.backlog()
+			return item, true
+		}
+	}
+	return item, false
+}
+
+// stealable returns the number of tasks in the deques.
+func (p *engine[T]) stealable() int64 {
+	deques := p.deques.Load()
+	if deques == nil {
+		return 0
+	}
+	var n int64
+	for _, d := range *deques {
+		n += atomic.LoadInt64(&d.size)
+	}
+	return n
+}
@@ -0,0 +1,132 @@
+package tinyPool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeque(t *testing.T) {
+	d := newDeque[int]()
+	for i := 0; i < 20; i++ {
+		d.push(i)
+	}
+
+	if item, _ := d.pop(); item != 0 {
+		t.Fatalf("pop() = %d, want the oldest 0", item)
+	}
+	if item, _ := d.steal(); item != 19 {
+		t.Fatalf("steal() = %d, want the newest 19", item)
+	}
+
+	items := d.close()
+	if len(items) != 18 || items[0] != 1 || items[17] != 18 {
+		t.Fatalf("close() = %v, want 1 to 18", items)
+	}
+	if d.push(20) {
+		t.Fatal("push() to a closed deque succeeded")
+	}
+	if _, ok := d.pop(); ok {
+		t.Fatal("pop() from a closed deque succeeded")
+	}
+}
+
+func TestWorkStealing(t *testing.T) {
+	p, _ := NewPool(2, WithWorkStealing(), WithDisablePurge(true))
+	defer p.Close()
+
+	blockA, blockB := make(chan struct{}), make(chan struct{})
+	started := make(chan struct{}, 2)
+	_ = p.Submit(func() { started <- struct{}{}; <-blockA })
+	_ = p.Submit(func() { started <- struct{}{}; <-blockB })
+	<-started
+	<-started
+
+	// both workers are busy, so the tasks are spread over their deques
+	var ran int64
+	done := make(chan struct{}, 10)
+	for i := 0; i < 10; i++ {
+		_ = p.Submit(func() { atomic.AddInt64(&ran, 1); done <- struct{}{} })
+	}
+	if n := p.Waiting(); n != 10 {
+		t.Fatalf("Waiting() = %d, want 10", n)
+	}
+
+	// one worker runs its own tasks and steals those of the other one
+	close(blockB)
+	for i := 0; i < 10; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("%d of 10 tasks ran while a worker was blocked", atomic.LoadInt64(&ran))
+		}
+	}
+	close(blockA)
+}
+
+func TestWorkStealingConcurrent(t *testing.T) {
+	p, _ := NewPool(4, WithWorkStealing())
+	defer p.Close()
+
+	var ran int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				_ = p.Submit(func() { atomic.AddInt64(&ran, 1) })
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran != 8000 {
+		t.Fatalf("%d tasks ran, want 8000", ran)
+	}
+}
+
+func TestWorkStealingDrain(t *testing.T) {
+	p, _ := NewPool(1, WithWorkStealing())
+	defer p.Close()
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	_ = p.Submit(func() { close(started); <-block })
+	<-started
+
+	for i := 0; i < 5; i++ {
+		_ = p.Submit(func() {})
+	}
+	tasks := p.Drain()
+	close(block)
+	if len(tasks) != 5 {
+		t.Fatalf("drained %d tasks, want 5", len(tasks))
+	}
+}
+
+func TestWorkStealingReboot(t *testing.T) {
+	p, _ := NewPool(2, WithWorkStealing(), WithPreAlloc(2))
+	defer p.Close()
+
+	for i := 0; i < 5; i++ {
+		p.Close()
+		p.Reboot()
+	}
+	done := make(chan struct{})
+	_ = p.Submit(func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("task did not run after Reboot")
+	}
+
+	if n := len(*p.deques.Load()); n > int(p.Cap()) {
+		t.Fatalf("%d deques for %d workers after Reboot", n, p.Cap())
+	}
+}
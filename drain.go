@@ -0,0 +1,36 @@
+package tinyPool
+
+import "sync/atomic"
+
+// Drain stops the pool from accepting tasks and returns the tasks which
+// have not started yet, in place of running them, so they can be saved or
+// sent elsewhere. Running tasks go on, Wait tells when they are done. A
+// returned function runs its task on the calling goroutine with its
+// interceptor, but without retries, as the pool is closed: a failing task
+// settles with its first error. Tasks whose deadline has passed are
+// dropped with ErrTaskExpired instead of being returned.
+func (p *Pool) Drain() []func() {
+	p.setClosed()
+	p.quit()
+
+	jobs := p.drain()
+	parked := p.tags.takeParked()
+	atomic.AddInt64(&p.pending, -int64(len(parked)))
+	for range parked {
+		p.unpark()
+	}
+	jobs = append(jobs, parked...)
+
+	tasks := make([]func(), 0, len(jobs))
+	for _, j := range jobs {
+		if p.stale(j) {
+			p.expire(j)
+			continue
+		}
+		j := j
+		tasks = append(tasks, func() {
+			p.handleError(p.call(j))
+		})
+	}
+	return tasks
+}
@@ -0,0 +1,72 @@
+package tinyPool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	p, _ := NewPool(2, WithTagLimits(map[string]int{"db": 1}))
+	defer p.Close()
+	p.Tune(2)
+
+	block := make(chan struct{})
+	started := make(chan struct{}, 2)
+	_ = p.Submit(func() { started <- struct{}{}; <-block }, WithTag("db"))
+	_ = p.Submit(func() { started <- struct{}{}; <-block })
+	<-started
+	<-started
+
+	ran := 0
+	for i := 0; i < 4; i++ {
+		_ = p.Submit(func() { ran++ })
+	}
+	_ = p.Submit(func() { ran++ }, WithTag("db"))
+
+	tasks := p.Drain()
+	if len(tasks) != 5 {
+		t.Fatalf("drained %d tasks, want 5", len(tasks))
+	}
+	if err := p.Submit(func() {}); err != ErrPoolClosed {
+		t.Fatalf("Submit() after Drain = %v, want ErrPoolClosed", err)
+	}
+
+	close(block)
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range tasks {
+		task()
+	}
+	if ran != 5 {
+		t.Fatalf("%d drained tasks ran, want 5", ran)
+	}
+}
+
+func TestDrainNoRetry(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+	p.Tune(1)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	_ = p.Submit(func() { close(started); <-block })
+	<-started
+
+	runs := 0
+	fail := errors.New("down")
+	f := SubmitResult(p, func() (int, error) { runs++; return 0, fail }, WithRetry(3, time.Millisecond))
+	tasks := p.Drain()
+	close(block)
+	for _, task := range tasks {
+		task()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := f.Get(ctx); err != fail || runs != 1 {
+		t.Fatalf("drained task ran %d times and settled with %v, want once with %v", runs, err, fail)
+	}
+}
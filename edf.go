@@ -0,0 +1,90 @@
+package tinyPool
+
+import (
+	"container/heap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// edfQueue pops the queued task with the earliest deadline first. Tasks
+// without a deadline come last, in submission order.
+type edfQueue struct {
+	mu    sync.Mutex
+	items edfHeap
+	seq   uint64
+	size  int64
+}
+
+type edfItem struct {
+	v        interface{}
+	deadline time.Time
+	seq      uint64
+}
+
+func newEDFQueue() *edfQueue {
+	return &edfQueue{}
+}
+
+func (q *edfQueue) Push(v interface{}) bool {
+	item := edfItem{v: v}
+	if j, ok := v.(*job); ok {
+		item.deadline = j.deadline
+	}
+
+	q.mu.Lock()
+	q.seq++
+	item.seq = q.seq
+	heap.Push(&q.items, item)
+	q.mu.Unlock()
+
+	atomic.AddInt64(&q.size, 1)
+	return true
+}
+
+func (q *edfQueue) Pop() interface{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 {
+		return nil
+	}
+	atomic.AddInt64(&q.size, -1)
+	return heap.Pop(&q.items).(edfItem).v
+}
+
+func (q *edfQueue) Empty() bool {
+	return q.Size() == 0
+}
+
+func (q *edfQueue) Size() int64 {
+	return atomic.LoadInt64(&q.size)
+}
+
+type edfHeap []edfItem
+
+func (h edfHeap) Len() int { return len(h) }
+
+func (h edfHeap) Less(i, j int) bool {
+	a, b := h[i], h[j]
+	if a.deadline.IsZero() != b.deadline.IsZero() {
+		return b.deadline.IsZero()
+	}
+	if !a.deadline.Equal(b.deadline) {
+		return a.deadline.Before(b.deadline)
+	}
+	return a.seq < b.seq
+}
+
+func (h edfHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *edfHeap) Push(x interface{}) { *h = append(*h, x.(edfItem)) }
+
+func (h *edfHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = edfItem{}
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,48 @@
+package tinyPool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEarliestDeadlineFirst(t *testing.T) {
+	p, _ := NewPool(1, WithEarliestDeadlineFirst())
+	defer p.Close()
+	p.Tune(1)
+
+	release := saturate(p)
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	now := time.Now()
+	for i := 3; i >= 0; i-- {
+		i := i
+		opts := []TaskOption{WithDeadline(now.Add(time.Duration(i+1) * time.Hour))}
+		if i == 3 {
+			opts = nil
+		}
+		wg.Add(1)
+		_ = p.Submit(func() {
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			wg.Done()
+		}, opts...)
+	}
+
+	release()
+	wg.Wait()
+
+	// the task without deadline comes last, unless the dispatcher has
+	// already taken it from the queue
+	if order[0] == 3 {
+		order = append(order[1:], 3)
+	}
+	for i, n := range order {
+		if n != i {
+			t.Fatalf("tasks ran in order %v", order)
+		}
+	}
+}
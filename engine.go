@@ -0,0 +1,1028 @@
+package tinyPool
+
+import (
+	"context"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// cacheLinePad is a cache line of padding, which keeps the fields before
+// and after it off each other's cache line.
+type cacheLinePad struct {
+	_ [64]byte
+}
+
+// engine is the worker machinery shared by Pool and PoolWithFunc.
+// Items of type T are handed to workers, which run them through exec.
+type engine[T any] struct {
+	// The counters written on every submission or task are grouped by the
+	// goroutines writing them, each group on cache lines of its own, so a
+	// write by a submitter doesn't stall the workers and the other way
+	// round. The 64 bit ones come first, and the groups take multiples of 8
+	// bytes, to keep them aligned on 32 bit CPUs.
+
+	// written by submitters: submitted counts tasks over the pool
+	// lifetime, burstUntil is when the latest burst window closes in Unix
+	// nanoseconds and nextPush picks the shard or deque of the next push
+	submitted  int64
+	burstUntil int64
+	jobNum     int32
+	nextPush   uint32
+	_          cacheLinePad
+
+	// written by workers: completed counts tasks over the pool lifetime,
+	// started the tasks which started and waited their total queue wait,
+	// lastWait is the queue wait of the latest started task and
+	// nextVictim picks the first deque to steal from
+	completed  int64
+	started    int64
+	waited     int64
+	lastWait   int64
+	idle       int32
+	nextVictim uint32
+	_          cacheLinePad
+
+	// pending is the number of accepted tasks which have not finished
+	// yet, written by both, inflight the number of tasks running under
+	// the concurrency limit
+	pending  int64
+	inflight int32
+	_        cacheLinePad
+
+	// capacity of the pool
+	capacity int32
+
+	//currently running goroutines
+	running int32
+
+	// shards queue the tasks while all workers are busy
+	shards []*shard[T]
+
+	// boxes recycles the boxes of the queued tasks
+	boxes sync.Pool
+
+	// deques are the deques of the workers with WorkStealing, stealSig
+	// wakes an idle worker up to steal
+	deques   atomic.Pointer[[]*deque[T]]
+	dequesMu sync.Mutex
+	stealSig chan struct{}
+
+	// limitSig wakes a worker waiting for the concurrency limit
+	limitSig chan struct{}
+
+	// slots bounds the number of queued tasks, nil if the queue is unbounded
+	slots chan struct{}
+
+	// stuck is the number of workers running a task for longer than
+	// the stuck worker limit
+	stuck int32
+
+	// discard is the number of oldest queued tasks to be dropped
+	discard int32
+
+	// blocking is the number of submitters waiting for a queue slot
+	blocking int32
+
+	//task queue -> task
+	task chan T
+
+	// stop asks one idle worker to exit
+	stop chan struct{}
+
+	// rejected counts tasks over the pool lifetime, dropped the ones
+	// dropped by CoDel or the rejection policy, expired the ones dropped
+	// because of their deadline
+	rejected int64
+	dropped  int64
+	expired  int64
+
+	// durations records how long tasks run, latencies in finer buckets
+	// for quantiles
+	durations histogram
+	latencies latencyHistogram
+
+	// waits records how long tasks wait between submission and start
+	waits latencyHistogram
+
+	// submittedAt returns when an item was submitted, deadlineOf the
+	// deadline for it to start, contextOf the context abandoning it,
+	// nameOf and tagOf its name and tag, traceOf its trace task
+	submittedAt func(T) time.Time
+	deadlineOf  func(T) time.Time
+	contextOf   func(T) context.Context
+	nameOf      func(T) string
+	tagOf       func(T) string
+	traceOf     func(T) *taskTrace
+
+	// activeTasks returns the tracked tasks shown by DebugHandler
+	activeTasks func() []TaskStatus
+
+	// bind attaches the state of the worker about to run an item to it
+	bind func(T, *WorkerState) T
+
+	// abort tells a dropped item the error it was dropped with, taskOf
+	// returns the task of an item for DroppedTask and RejectedTask
+	abort  func(T, error)
+	taskOf func(T) interface{}
+
+	// onClose is called when the pool stops accepting tasks
+	onClose func()
+
+	// observers are called with the duration of every task
+	observers atomic.Pointer[[]func(time.Duration)]
+
+	// recent holds the latest task durations shown by DebugHandler
+	recent recentDurations
+
+	// workers holds a *workerSlot for each started worker by its ID
+	workers   sync.Map
+	workerSeq int64
+
+	wg sync.WaitGroup
+
+	quitSig  chan struct{}
+	quitOnce sync.Once
+
+	// fed is closed when the dispatcher goroutines have exited, by the
+	// dispatcher setting fedClosed
+	fed       chan struct{}
+	fedClosed int32
+
+	// dispatching is 1 while the dispatcher runs, 0 while it is asleep
+	// with ScaleToZero
+	dispatching int32
+
+	// ctx is canceled when the pool is force closed
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// closing is canceled once the pool stops accepting tasks, or is
+	// force closed
+	closing    context.Context
+	closeTasks context.CancelFunc
+
+	// closed is set once the pool stops accepting tasks
+	closed int32
+
+	// size is the size the pool was created with, followProcs is set
+	// while its capacity follows GOMAXPROCS, until it is tuned
+	size        int32
+	followProcs int32
+
+	options *Options
+
+	// backlogged tells whether the queue is above the high backlog
+	// watermark
+	backlogged backlogState
+
+	// scaler holds the last measurements of the autoscaler
+	scaler autoscaler
+
+	// governor holds the last measurement of the CPU governor, cpuLimit
+	// is the number of tasks it lets run at once, 0 while it doesn't
+	// throttle
+	governor cpuSample
+	cpuLimit int32
+
+	// limiter throttles task starts, nil if there is no rate limit
+	limiter *rate.Limiter
+
+	exec func(T)
+}
+
+func newEngine[T any](size int, exec func(T), options ...Option) *engine[T] {
+	opts := loadOptions(options...)
+
+	// the pool runs at least as many workers as the process has CPUs
+	cap := defaultSize()
+	if cap < size {
+		cap = size
+	}
+
+	p := &engine[T]{
+		capacity: int32(cap),
+		running:  int32(0),
+		size:     int32(size),
+		task:     make(chan T),
+		stop:     make(chan struct{}),
+		stealSig: make(chan struct{}, 1),
+		limitSig: make(chan struct{}, 1),
+		options:  opts,
+		exec:     exec,
+	}
+	p.shards = p.newShards()
+	p.boxes.New = func() interface{} { return new(T) }
+
+	if opts.AutoscaleMax <= 0 {
+		p.followProcs = 1
+	}
+	if opts.QueueCap > 0 {
+		p.slots = make(chan struct{}, opts.QueueCap)
+	}
+	if opts.RateLimit > 0 {
+		burst := opts.RateBurst
+		if burst < 1 {
+			burst = 1
+		}
+		p.limiter = rate.NewLimiter(opts.RateLimit, burst)
+	}
+
+	p.start()
+
+	return p
+}
+
+// start opens the pool and launches its dispatcher.
+func (p *engine[T]) start() {
+	p.quitSig = make(chan struct{})
+	p.quitOnce = sync.Once{}
+	p.fed = make(chan struct{})
+	atomic.StoreInt32(&p.fedClosed, 0)
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.closing, p.closeTasks = context.WithCancel(p.ctx)
+	atomic.StoreInt32(&p.closed, 0)
+
+	// with ScaleToZero the dispatcher starts with the first worker
+	atomic.StoreInt32(&p.dispatching, 0)
+	if !p.options.ScaleToZero {
+		p.wake()
+	}
+
+	warm := p.options.PreAlloc
+	if warm < p.options.MinWorkers {
+		warm = p.options.MinWorkers
+	}
+	for i := 0; i < warm; i++ {
+		if !p.tryStartWorker() {
+			break
+		}
+	}
+}
+
+func (p *engine[T]) submit(item T) error {
+	if p.IsClosed() {
+		p.reject(item, ErrPoolClosed)
+		return ErrPoolClosed
+	}
+	if p.overLimit() {
+		p.reject(item, ErrLimitExceeded)
+		return ErrLimitExceeded
+	}
+	if err := p.admitMemory(true); err != nil {
+		p.reject(item, err)
+		return err
+	}
+	if p.missesSLO() {
+		p.reject(item, ErrWouldMissSLO)
+		return ErrWouldMissSLO
+	}
+	return p.place(item)
+}
+
+// place hands item, admitted to the pool, to a worker or queues it.
+func (p *engine[T]) place(item T) error {
+	atomic.AddInt64(&p.pending, 1)
+	if p.dropStale(item) {
+		// already past its deadline, as after waiting in a lane
+		return nil
+	}
+	if p.handoff(item) {
+		p.accept(item)
+		return nil
+	}
+	discarded, err := p.enqueue(item)
+	if discarded {
+		// counted as dropped, not as submitted; the drop already aborted
+		// item, so an error of the policy must not complete it again
+		return nil
+	}
+	if err != nil {
+		atomic.AddInt64(&p.pending, -1)
+		p.reject(item, err)
+		p.queueFull(item, err)
+		return err
+	}
+
+	p.accept(item)
+	return nil
+}
+
+// trySubmit is like submit but never blocks. It reports false when the
+// pool is closed or neither an idle worker nor a queue slot is available.
+func (p *engine[T]) trySubmit(item T) bool {
+	if p.IsClosed() {
+		p.reject(item, ErrPoolClosed)
+		return false
+	}
+	if p.overLimit() {
+		p.reject(item, ErrLimitExceeded)
+		return false
+	}
+	if err := p.admitMemory(false); err != nil {
+		p.reject(item, err)
+		return false
+	}
+	if p.missesSLO() {
+		p.reject(item, ErrWouldMissSLO)
+		return false
+	}
+
+	atomic.AddInt64(&p.pending, 1)
+	if !p.handoff(item) {
+		if p.slots != nil {
+			select {
+			case p.slots <- struct{}{}:
+			default:
+				atomic.AddInt64(&p.pending, -1)
+				p.reject(item, ErrQueueFull)
+				p.queueFull(item, ErrQueueFull)
+				return false
+			}
+		}
+		p.push(item)
+	}
+
+	p.accept(item)
+	return true
+}
+
+// handoff passes item straight to a worker, bypassing the queue: to a new
+// one while the pool may grow, else to an idle one waiting for a task. It
+// reports false if all workers are busy. A worker with WorkerInit may fail
+// to start, so it never gets a task this way.
+func (p *engine[T]) handoff(item T) bool {
+	limit := p.growLimit()
+	if p.options.WorkerInit == nil {
+		if p.reserveWorker(limit) {
+			// copied here, so it only escapes with the new worker
+			first := item
+			p.startOneWorker(&first)
+			return true
+		}
+	} else if p.reserveWorker(limit) {
+		p.startOneWorker(nil)
+	}
+
+	if atomic.LoadInt32(&p.idle) == 0 {
+		return p.options.BurstExtra > 0 && p.burst(item)
+	}
+	select {
+	case p.task <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+// accept counts item as submitted.
+func (p *engine[T]) accept(item T) {
+	atomic.AddInt32(&p.jobNum, 1)
+	atomic.AddInt64(&p.submitted, 1)
+	if h := p.options.Hooks.OnSubmit; h != nil {
+		h(p.name(item))
+	}
+}
+
+// reject counts item as refused with err.
+func (p *engine[T]) reject(item T, err error) {
+	atomic.AddInt64(&p.rejected, 1)
+	p.log(LevelWarn, "task rejected", "task", p.name(item), "err", err)
+	p.traced(item).end(err)
+	if h := p.options.Hooks.OnReject; h != nil {
+		h(p.name(item), err)
+	}
+}
+
+// name returns the name of item, if it has one.
+func (p *engine[T]) name(item T) string {
+	if p.nameOf == nil {
+		return ""
+	}
+	return p.nameOf(item)
+}
+
+// tag returns the tag of item, if it has one.
+func (p *engine[T]) tag(item T) string {
+	if p.tagOf == nil {
+		return ""
+	}
+	return p.tagOf(item)
+}
+
+// payload returns the task of item shown to callbacks, if it has one.
+func (p *engine[T]) payload(item T) interface{} {
+	if p.taskOf == nil {
+		return nil
+	}
+	return p.taskOf(item)
+}
+
+// enqueue pushes item to the task queue. When the queue is bounded and full,
+// the rejection policy decides what happens to item. It reports whether
+// the policy discarded item.
+func (p *engine[T]) enqueue(item T) (discarded bool, err error) {
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			r := &rejection[T]{p: p, item: item}
+			err := p.options.RejectionPolicy.Reject(r)
+			return r.discarded, err
+		}
+	}
+
+	p.push(item)
+	return false, nil
+}
+
+// push queues item to a worker with WorkStealing, or to a shard.
+func (p *engine[T]) push(item T) {
+	if !p.options.WorkStealing || !p.pushLocal(item) {
+		p.pushShard(item)
+	}
+	p.backlog()
+}
+
+// pushShard queues item and wakes the feeder of its shard up.
+func (p *engine[T]) pushShard(item T) {
+	s := p.pick()
+	s.q.Push(p.box(item))
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+	p.wake()
+}
+
+// wake starts the dispatcher unless it is running.
+func (p *engine[T]) wake() {
+	if atomic.LoadInt32(&p.dispatching) == 0 && atomic.CompareAndSwapInt32(&p.dispatching, 0, 1) {
+		go p.dispatch()
+	}
+}
+
+// dispatch runs the feeders and purges idle workers until the pool quits.
+// With ScaleToZero it exits once no worker is left, to be started again
+// by wake.
+func (p *engine[T]) dispatch() {
+	for {
+		if quit := p.serve(); quit {
+			if atomic.CompareAndSwapInt32(&p.fedClosed, 0, 1) {
+				close(p.fed)
+			}
+			return
+		}
+
+		// a worker started, a task queued or the pool closed meanwhile
+		// found the dispatcher awake, so it checks for them once asleep
+		atomic.StoreInt32(&p.dispatching, 0)
+		if p.Running() == 0 && p.Waiting() == 0 && !p.IsClosed() {
+			p.log(LevelDebug, "dispatcher asleep")
+			return
+		}
+		if !atomic.CompareAndSwapInt32(&p.dispatching, 0, 1) {
+			// woken up again already
+			return
+		}
+	}
+}
+
+// serve runs the feeders and purges idle workers until the pool quits, or
+// with ScaleToZero until no worker is left. It reports whether the pool
+// quit.
+func (p *engine[T]) serve() (quit bool) {
+	var purge <-chan time.Time
+	if !p.options.DisablePurge {
+		ticker := time.NewTicker(p.options.ExpiryDuration)
+		defer ticker.Stop()
+		purge = ticker.C
+	}
+
+	var autoscale <-chan time.Time
+	if p.options.AutoscaleMax > 0 {
+		ticker := time.NewTicker(p.options.AutoscaleInterval)
+		defer ticker.Stop()
+		autoscale = ticker.C
+		p.scaler = p.measure()
+	}
+
+	var govern <-chan time.Time
+	if p.options.CPUThreshold > 0 {
+		ticker := time.NewTicker(p.options.CPUInterval)
+		defer ticker.Stop()
+		govern = ticker.C
+		p.governor = sampleCPU()
+	}
+
+	var procs <-chan time.Time
+	if atomic.LoadInt32(&p.followProcs) == 1 {
+		ticker := time.NewTicker(procsInterval)
+		defer ticker.Stop()
+		procs = ticker.C
+		p.Resize()
+	}
+
+	// sleep stops the feeders once their queue is empty
+	sleep := make(chan struct{})
+	var feeders sync.WaitGroup
+	feeders.Add(len(p.shards))
+	for _, s := range p.shards {
+		go func(s *shard[T]) {
+			defer feeders.Done()
+			p.feed(s, sleep)
+		}(s)
+	}
+	defer feeders.Wait()
+
+	for {
+		n := atomic.LoadInt32(&p.jobNum)
+		select {
+		case <-p.quitSig:
+			return true
+
+		case <-autoscale:
+			p.autoscale()
+
+		case <-procs:
+			p.Resize()
+
+		case <-govern:
+			p.govern()
+
+		case <-purge:
+			if n != atomic.LoadInt32(&p.jobNum) || p.Waiting() != 0 {
+				continue
+			}
+			if p.Running() > p.keepAlive() {
+				p.log(LevelDebug, "purging idle worker", "running", p.Running())
+				p.stopOneWorker()
+			} else if p.options.ScaleToZero && p.Running() == 0 {
+				close(sleep)
+				return false
+			}
+		}
+	}
+}
+
+// Close stops the pool and waits for running workers to exit.
+// Tasks still waiting in the queue are abandoned.
+func (p *engine[T]) Close() {
+	p.setClosed()
+	p.quit()
+	p.wg.Wait()
+}
+
+// Shutdown stops accepting tasks, waits for all queued and running tasks
+// to finish and then closes the pool. If ctx is done first, Shutdown
+// returns ctx.Err() and the workers go on draining the queue.
+func (p *engine[T]) Shutdown(ctx context.Context) error {
+	p.setClosed()
+
+	if err := p.Wait(ctx); err != nil {
+		return err
+	}
+
+	p.Close()
+	return nil
+}
+
+// Wait blocks until the queue is empty and no task is running, or returns
+// ctx.Err() if ctx is done first. The pool keeps accepting tasks, which
+// Wait also waits for. Tasks scheduled to run later, such as retries and
+// delayed tasks, are not waited for until they are submitted.
+func (p *engine[T]) Wait(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for atomic.LoadInt64(&p.pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// ForceClose closes the pool at once. Queued tasks are dropped and the
+// context of running tasks is canceled. It returns the number of dropped
+// tasks.
+func (p *engine[T]) ForceClose() int {
+	dropped := p.kill()
+	p.wg.Wait()
+	return dropped
+}
+
+// CloseTimeout stops accepting tasks and gives the queued and running ones
+// up to d to finish. If they don't, the pool is force closed without
+// waiting for the workers, and CloseTimeout returns ErrTimeout with the
+// number of tasks that were dropped or still running.
+func (p *engine[T]) CloseTimeout(d time.Duration) (remaining int, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	if p.Shutdown(ctx) == nil {
+		return 0, nil
+	}
+
+	dropped := p.kill()
+	return dropped + int(atomic.LoadInt64(&p.pending)), ErrTimeout
+}
+
+// kill stops the pool, cancels running tasks and drops queued ones.
+func (p *engine[T]) kill() int {
+	p.setClosed()
+	p.cancel()
+	p.quit()
+
+	return len(p.drain())
+}
+
+// drain empties the queue of a quitting pool and returns the tasks which
+// have not been handed to a worker.
+func (p *engine[T]) drain() []T {
+	// wait for the feeder, so the queue has a single consumer again
+	<-p.fed
+
+	var items []T
+	for _, s := range p.shards {
+		items = append(items, s.leftover...)
+		atomic.AddInt64(&p.pending, -int64(len(s.leftover)))
+		s.leftover = nil
+		for s.q.Size() > 0 {
+			item := s.q.Pop()
+			if item == nil {
+				// a push is still linking its item in
+				runtime.Gosched()
+				continue
+			}
+			atomic.AddInt64(&p.pending, -1)
+			if p.dequeued() {
+				items = append(items, p.unbox(item))
+			}
+		}
+	}
+	if deques := p.deques.Load(); deques != nil {
+		for _, d := range *deques {
+			for _, item := range d.close() {
+				atomic.AddInt64(&p.pending, -1)
+				if p.dequeued() {
+					items = append(items, item)
+				}
+			}
+		}
+	}
+
+	return items
+}
+
+// Reboot reopens a closed pool. Tasks abandoned in the queue by Close are
+// dropped.
+func (p *engine[T]) Reboot() {
+	if !p.IsClosed() {
+		return
+	}
+
+	p.Close()
+	p.drain()
+	// the deques of the workers stopped by Close are drained, forget them
+	p.dequesMu.Lock()
+	p.deques.Store(nil)
+	p.dequesMu.Unlock()
+	p.cancel()
+	p.start()
+}
+
+// quit stops the workers and the dispatcher, which is woken up to exit if
+// it is asleep.
+func (p *engine[T]) quit() {
+	p.quitOnce.Do(func() {
+		close(p.quitSig)
+	})
+	p.wake()
+}
+
+// setClosed stops the pool from accepting tasks.
+func (p *engine[T]) setClosed() {
+	atomic.StoreInt32(&p.closed, 1)
+	p.closeTasks()
+	if p.onClose != nil {
+		p.onClose()
+	}
+}
+
+// IsClosed indicates whether the pool has stopped accepting tasks.
+func (p *engine[T]) IsClosed() bool {
+	return atomic.LoadInt32(&p.closed) == 1
+}
+
+// Running returns the number of workers currently started.
+func (p *engine[T]) Running() int32 {
+	return int32(atomic.LoadInt32(&p.running))
+}
+
+// Cap returns the capacity of the pool.
+func (p *engine[T]) Cap() int32 {
+	return atomic.LoadInt32(&p.capacity)
+}
+
+// Free returns the number of workers which may still be started.
+func (p *engine[T]) Free() int32 {
+	if free := p.Cap() - p.Running(); free > 0 {
+		return free
+	}
+	return 0
+}
+
+// Idle returns the number of started workers waiting for a task.
+func (p *engine[T]) Idle() int32 {
+	return atomic.LoadInt32(&p.idle)
+}
+
+// Waiting returns the number of tasks in the queue, and in the deques of
+// the workers with WorkStealing.
+func (p *engine[T]) Waiting() int {
+	return int(p.queued() + p.stealable())
+}
+
+// Tune changes the capacity of the pool. When the pool shrinks, surplus
+// workers retire as soon as they finish their current task. A tuned pool
+// no longer follows GOMAXPROCS, see Resize.
+func (p *engine[T]) Tune(size int) {
+	if size <= 0 {
+		return
+	}
+	atomic.StoreInt32(&p.followProcs, 0)
+	p.tune(size)
+}
+
+// tune changes the capacity of the pool to size.
+func (p *engine[T]) tune(size int) {
+	if size <= 0 || int32(size) == p.Cap() {
+		return
+	}
+	atomic.StoreInt32(&p.capacity, int32(size))
+
+	// wake idle surplus workers, busy ones retire after their task
+retire:
+	for surplus := p.Running() - int32(size); surplus > 0; surplus-- {
+		select {
+		case p.stop <- struct{}{}:
+		default:
+			break retire
+		}
+	}
+
+	// pick up the backlog with the extra capacity
+	for n := p.Waiting(); n > 0; n-- {
+		if !p.tryStartWorker() {
+			break
+		}
+	}
+}
+
+// tryStartWorker starts a new worker unless the pool is at capacity.
+func (p *engine[T]) tryStartWorker() bool {
+	return p.tryStartWorkerWith(nil)
+}
+
+// tryStartWorkerWith is like tryStartWorker, the new worker runs first
+// before any other task unless it is nil.
+func (p *engine[T]) tryStartWorkerWith(first *T) bool {
+	if !p.reserveWorker(p.limit()) {
+		return false
+	}
+	p.startOneWorker(first)
+	return true
+}
+
+// keepAlive returns the number of workers the purge leaves running.
+func (p *engine[T]) keepAlive() int32 {
+	if p.options.CoreSize > p.options.MinWorkers {
+		return int32(p.options.CoreSize)
+	}
+	return int32(p.options.MinWorkers)
+}
+
+// reserveWorker counts a worker about to start unless limit workers are
+// running already.
+func (p *engine[T]) reserveWorker(limit int32) bool {
+	for {
+		running := p.Running()
+		if running >= limit {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&p.running, running, running+1) {
+			return true
+		}
+	}
+}
+
+func (p *engine[T]) startOneWorker(first *T) {
+	w := p.register()
+	p.log(LevelDebug, "worker started", "worker", w.info.ID, "running", p.Running())
+	p.wake()
+	p.wg.Add(1)
+	go p.worker(w, first)
+}
+
+func (p *engine[T]) stopOneWorker() {
+	select {
+	case p.stop <- struct{}{}:
+	case <-p.quitSig:
+	}
+}
+
+// worker runs tasks until it retires. A worker started by a submitter runs
+// first, unless nil, before it takes other tasks.
+func (p *engine[T]) worker(w *workerSlot, first *T) {
+	defer p.wg.Done()
+
+	// a recycled worker is replaced once it no longer counts as running
+	recycled := false
+	defer func() {
+		if recycled && !p.IsClosed() {
+			p.tryStartWorker()
+		}
+	}()
+
+	defer atomic.AddInt32(&p.running, -1)
+
+	defer p.workers.Delete(w.info.ID)
+	if p.options.LockOSThread {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+	if init := p.options.WorkerInit; init != nil {
+		if err := init(); err != nil {
+			p.log(LevelError, "worker init failed", "worker", w.info.ID, "err", err)
+			return
+		}
+	}
+	if teardown := p.options.WorkerTeardown; teardown != nil {
+		defer teardown()
+	}
+	ws := p.newWorkerState(w.info.ID)
+	defer p.releaseWorkerState(ws)
+	if h := p.options.Hooks.OnWorkerStart; h != nil {
+		h(w.info.ID)
+	}
+	if h := p.options.Hooks.OnWorkerStop; h != nil {
+		defer h(w.info.ID)
+	}
+
+	// the worker takes tasks from the feeder of its shard, and from any
+	// feeder or submitter on the engine channel
+	lane := p.shards[w.info.ID%len(p.shards)].task
+
+	// with WorkStealing the worker runs the tasks of its deque, or stolen
+	// from peers, before waiting on the channels
+	var d *deque[T]
+	var wake, steal chan struct{}
+	if p.options.WorkStealing {
+		d = p.addDeque()
+		wake, steal = d.wake, p.stealSig
+		defer func() {
+			select {
+			case <-p.quitSig:
+				// left for drain
+			default:
+				p.removeDeque(d)
+			}
+		}()
+	}
+
+	tasks := 0
+	atomic.AddInt32(&p.idle, 1)
+	for {
+		var item T
+		var ok bool
+		if first != nil {
+			item, ok, first = *first, true, nil
+		} else if d != nil {
+			if item, ok = p.local(d); ok && !p.dequeued() {
+				p.discardOldest(item)
+				continue
+			}
+			if ok && p.dropStale(item) {
+				continue
+			}
+		}
+		if !ok {
+			select {
+			case item = <-p.task:
+			case item = <-lane:
+			case <-wake:
+				continue
+			case <-steal:
+				continue
+
+			case <-p.stop:
+				atomic.AddInt32(&p.idle, -1)
+				p.log(LevelDebug, "worker stopped", "worker", w.info.ID, "reason", "idle")
+				return
+
+			case <-p.quitSig:
+				atomic.AddInt32(&p.idle, -1)
+				p.log(LevelDebug, "worker stopped", "worker", w.info.ID, "reason", "close")
+				return
+			}
+		}
+
+		atomic.AddInt32(&p.idle, -1)
+		if p.bind != nil {
+			item = p.bind(item, ws)
+		}
+		w.begin(p.name(item), p.tag(item))
+		p.run(item)
+		w.end()
+		if max := p.options.MaxTasksPerWorker; max > 0 {
+			if tasks++; tasks >= max {
+				p.log(LevelDebug, "worker stopped", "worker", w.info.ID, "reason", "recycled")
+				recycled = true
+				return
+			}
+		}
+		if p.Running() > p.limit() {
+			// the pool has been shrunk by Tune, or a stuck
+			// worker has been replaced
+			p.log(LevelDebug, "worker stopped", "worker", w.info.ID, "reason", "tune")
+			return
+		}
+		atomic.AddInt32(&p.idle, 1)
+	}
+}
+
+// run executes item and marks it as finished. A panic raised by item is
+// recovered and passed to the panic handler, so the worker stays alive.
+func (p *engine[T]) run(item T) {
+	p.runFunc(item, p.exec)
+}
+
+// runFunc is run with exec in place of p.exec.
+func (p *engine[T]) runFunc(item T, exec func(T)) {
+	defer p.done()
+	defer p.recoverPanic(item)
+
+	p.throttle()
+	limited := p.admit()
+	start := time.Now()
+	if limited {
+		defer p.releaseLimit(start)
+	}
+	var wait time.Duration
+	if p.submittedAt != nil {
+		wait = start.Sub(p.submittedAt(item))
+		p.waits.add(wait)
+		atomic.AddInt64(&p.started, 1)
+		atomic.AddInt64(&p.waited, int64(wait))
+		atomic.StoreInt64(&p.lastWait, int64(wait))
+	}
+	if h := p.options.Hooks.OnStart; h != nil {
+		h(p.name(item), wait)
+	}
+	tt := p.traced(item)
+	tt.started(wait)
+	defer tt.end(nil)
+
+	defer p.observe(item, start)
+	defer p.watchSlow(item, start)()
+	defer p.watchStuck(item, start)()
+	tt.region(func() { p.profiled(item, exec) })
+}
+
+// done marks a task as finished.
+func (p *engine[T]) done() {
+	atomic.AddInt64(&p.completed, 1)
+	atomic.AddInt64(&p.pending, -1)
+}
+
+// recoverPanic passes a panic of the running item to the panic handler.
+// It must be deferred directly.
+func (p *engine[T]) recoverPanic(item T) {
+	if r := recover(); r != nil {
+		if h := p.options.Hooks.OnPanic; h != nil {
+			h(p.name(item), r)
+		}
+		stack := debug.Stack()
+		p.log(LevelError, "task panicked", "task", p.name(item), "panic", r, "stack", string(stack))
+		if p.options.PanicHandler != nil {
+			p.options.PanicHandler(r, stack)
+		} else if p.options.Logger == nil {
+			log.Printf("tinyPool: task panicked: %v\n%s", r, stack)
+		}
+	}
+}
+
+// throttle waits until the rate limit lets another task start.
+func (p *engine[T]) throttle() {
+	if p.limiter != nil {
+		// only fails when the pool is force closed, run the task anyway
+		_ = p.limiter.Wait(p.ctx)
+	}
+}
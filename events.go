@@ -0,0 +1,81 @@
+package tinyPool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// eventBufferSize is the capacity of the channel returned by Pool.Done.
+const eventBufferSize = 1024
+
+// TaskEvent describes a finished task.
+type TaskEvent struct {
+	// Name is the name given to the task with WithTaskName.
+	Name string
+
+	// Duration is how long the task ran.
+	Duration time.Duration
+
+	// Err is the error returned by the task, if it reports one.
+	Err error
+
+	// Panic is the value recovered from the task if it panicked.
+	Panic interface{}
+}
+
+// Done returns a channel which receives a TaskEvent for every task
+// finished after the first call to Done. Events are dropped while the
+// channel is full, so the subscriber should keep reading it.
+func (p *Pool) Done() <-chan TaskEvent {
+	p.eventsOnce.Do(func() {
+		p.events = make(chan TaskEvent, eventBufferSize)
+		atomic.StoreInt32(&p.subscribed, 1)
+	})
+	return p.events
+}
+
+// runTask runs j on a worker, then passes the concurrency slot of its tag
+// to the next task waiting for it.
+func (p *Pool) runTask(j job) {
+	if p.tags.limited(j.tag) {
+		defer p.releaseTag(j.tag)
+	}
+	p.execTask(j)
+}
+
+// execTask runs j and publishes its TaskEvent.
+func (p *Pool) execTask(j job) {
+	if atomic.LoadInt32(&p.subscribed) == 0 {
+		p.handleError(p.call(j))
+		return
+	}
+
+	ev := TaskEvent{Name: j.name}
+	start := time.Now()
+	defer func() {
+		ev.Duration = time.Since(start)
+		if r := recover(); r != nil {
+			ev.Panic = r
+			p.publish(ev)
+			panic(r)
+		}
+		p.publish(ev)
+	}()
+
+	ev.Err = p.call(j)
+	p.handleError(ev.Err)
+}
+
+// handleError passes the error of a failed task to the error handler.
+func (p *Pool) handleError(err error) {
+	if err != nil && p.options.ErrorHandler != nil {
+		p.options.ErrorHandler(err)
+	}
+}
+
+func (p *Pool) publish(ev TaskEvent) {
+	select {
+	case p.events <- ev:
+	default:
+	}
+}
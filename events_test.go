@@ -0,0 +1,44 @@
+package tinyPool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDone(t *testing.T) {
+	p, _ := NewPool(1, WithPanicHandler(func(interface{}, []byte) {}))
+	defer p.Close()
+
+	events := p.Done()
+
+	_ = p.Submit(func() {
+		time.Sleep(time.Millisecond)
+	}, WithTaskName("sleep"))
+
+	ev := <-events
+	if ev.Name != "sleep" || ev.Duration < time.Millisecond || ev.Err != nil || ev.Panic != nil {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+
+	_ = p.Submit(func() {
+		panic("boom")
+	}, WithTaskName("panic"))
+
+	ev = <-events
+	if ev.Name != "panic" || ev.Panic != "boom" {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+
+	errBad := errors.New("bad")
+	f := SubmitResult(p, func() (int, error) {
+		return 0, errBad
+	}, WithTaskName("future"))
+	_, _ = f.Get(context.Background())
+
+	ev = <-events
+	if ev.Name != "future" || ev.Err != errBad {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+}
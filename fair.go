@@ -0,0 +1,133 @@
+package tinyPool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Producer submits tasks to a pool on behalf of one subsystem. With
+// WithFairQueueing, the queued tasks of each producer are handed out in
+// proportion to its weight, so a busy producer cannot starve the others.
+type Producer struct {
+	p      *Pool
+	name   string
+	weight int
+}
+
+// Producer returns a submitter handle with the given weight, at least 1.
+// Tasks submitted directly to the pool share a flow of weight 1.
+func (p *Pool) Producer(name string, weight int) *Producer {
+	if weight < 1 {
+		weight = 1
+	}
+	return &Producer{p: p, name: name, weight: weight}
+}
+
+// Name returns the name of the producer.
+func (pr *Producer) Name() string {
+	return pr.name
+}
+
+// Submit submits a task to the pool as pr.
+func (pr *Producer) Submit(task func(), opts ...TaskOption) error {
+	return pr.p.Submit(task, append(opts[:len(opts):len(opts)], pr.option)...)
+}
+
+// SubmitErr submits a task which may fail to the pool as pr.
+func (pr *Producer) SubmitErr(task func() error, opts ...TaskOption) error {
+	return pr.p.SubmitErr(task, append(opts[:len(opts):len(opts)], pr.option)...)
+}
+
+func (pr *Producer) option(j *job) {
+	j.producer = pr
+}
+
+// fairQueue is a weighted round robin over a FIFO per producer. Each turn
+// a producer hands out up to its weight in tasks before the next one.
+type fairQueue struct {
+	mu    sync.Mutex
+	flows map[*Producer]*flow
+
+	// active lists the flows with queued tasks in round robin order
+	active []*flow
+	next   int
+
+	// credit is the number of tasks the current flow may still hand out
+	credit int
+
+	size int64
+}
+
+type flow struct {
+	producer *Producer
+	weight   int
+	items    []interface{}
+}
+
+func newFairQueue() *fairQueue {
+	return &fairQueue{flows: make(map[*Producer]*flow)}
+}
+
+func (q *fairQueue) Push(v interface{}) bool {
+	var pr *Producer
+	if j, ok := v.(*job); ok {
+		pr = j.producer
+	}
+
+	q.mu.Lock()
+	f := q.flows[pr]
+	if f == nil {
+		f = &flow{producer: pr, weight: 1}
+		if pr != nil {
+			f.weight = pr.weight
+		}
+		q.flows[pr] = f
+	}
+	if len(f.items) == 0 {
+		q.active = append(q.active, f)
+	}
+	f.items = append(f.items, v)
+	q.mu.Unlock()
+
+	atomic.AddInt64(&q.size, 1)
+	return true
+}
+
+func (q *fairQueue) Pop() interface{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.active) == 0 {
+		return nil
+	}
+	if q.credit == 0 {
+		q.next %= len(q.active)
+		q.credit = q.active[q.next].weight
+	}
+
+	f := q.active[q.next]
+	v := f.items[0]
+	f.items[0] = nil
+	f.items = f.items[1:]
+	q.credit--
+
+	if len(f.items) == 0 {
+		// the flow leaves the round, forget it so idle producers cost nothing
+		q.active = append(q.active[:q.next], q.active[q.next+1:]...)
+		delete(q.flows, f.producer)
+		q.credit = 0
+	} else if q.credit == 0 {
+		q.next++
+	}
+
+	atomic.AddInt64(&q.size, -1)
+	return v
+}
+
+func (q *fairQueue) Empty() bool {
+	return q.Size() == 0
+}
+
+func (q *fairQueue) Size() int64 {
+	return atomic.LoadInt64(&q.size)
+}
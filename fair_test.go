@@ -0,0 +1,69 @@
+package tinyPool
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestFairQueue(t *testing.T) {
+	a, b := &Producer{weight: 3}, &Producer{weight: 1}
+
+	q := newFairQueue()
+	for i := 0; i < 8; i++ {
+		q.Push(&job{name: "a", producer: a})
+	}
+	for i := 0; i < 4; i++ {
+		q.Push(&job{name: "b", producer: b})
+	}
+
+	var got string
+	for q.Size() > 0 {
+		got += q.Pop().(*job).name
+	}
+	if want := "aaabaaabaabb"; got != want {
+		t.Fatalf("popped %s, want %s", got, want)
+	}
+}
+
+func TestWithFairQueueing(t *testing.T) {
+	p, _ := NewPool(1, WithFairQueueing())
+	defer p.Close()
+	p.Tune(1)
+
+	chatty, quiet := p.Producer("chatty", 1), p.Producer("quiet", 1)
+
+	release := saturate(p)
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	wg.Add(12)
+	for i := 0; i < 10; i++ {
+		_ = chatty.Submit(func() {
+			defer wg.Done()
+			mu.Lock()
+			order = append(order, "c")
+			mu.Unlock()
+		})
+	}
+	for i := 0; i < 2; i++ {
+		_ = quiet.Submit(func() {
+			defer wg.Done()
+			mu.Lock()
+			order = append(order, "q")
+			mu.Unlock()
+		})
+	}
+	release()
+	wg.Wait()
+
+	// the quiet producer is served within the first few turns
+	last := 0
+	for i, s := range order {
+		if s == "q" {
+			last = i
+		}
+	}
+	if last > 5 {
+		t.Fatalf("quiet producer starved: %v", order)
+	}
+}
@@ -0,0 +1,138 @@
+package tinyPool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FanOut runs fn on p for every item read from in, with at most the
+// capacity of p running at once, and sends the results to the returned
+// channel in completion order. The channel is closed once in is closed
+// and all results are sent, or ctx is done, or p refuses a task.
+func FanOut[T, R any](ctx context.Context, p *Pool, in <-chan T, fn func(context.Context, T) R) <-chan R {
+	out := make(chan R)
+	go func() {
+		defer close(out)
+		process(ctx, p, in, int(p.Cap()), out, func(ctx context.Context, v T) (R, error) {
+			return fn(ctx, v), nil
+		}, func(error) {})
+	}()
+	return out
+}
+
+// FanIn merges chans into the returned channel, which is closed once all
+// of chans are closed or ctx is done.
+func FanIn[T any](ctx context.Context, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	var wg sync.WaitGroup
+	wg.Add(len(chans))
+	for _, ch := range chans {
+		go func(ch <-chan T) {
+			defer wg.Done()
+			for v := range ch {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(ch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Consume runs fn on p for every item read from ch, with at most the
+// capacity of p running at once, until ch is closed or ctx is done. It
+// waits for the started tasks, then returns ctx.Err() if ctx was done or
+// the error of Submit if p refused a task.
+func Consume[T any](ctx context.Context, p *Pool, ch <-chan T, fn func(T)) error {
+	var err error
+	var once sync.Once
+	fail := func(e error) {
+		once.Do(func() { err = e })
+	}
+
+	process(ctx, p, ch, int(p.Cap()), nil, func(_ context.Context, v T) (struct{}, error) {
+		fn(v)
+		return struct{}{}, nil
+	}, fail)
+
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
+// process runs fn on p for every item of in, at most workers at once, and
+// sends the results to out, unless out is nil. It returns when in is closed
+// and all tasks have finished, or ctx is done. Errors of fn and of Submit,
+// panics of fn and the errors of dropped tasks are passed to fail, a Submit
+// error also ends process.
+func process[In, Out any](ctx context.Context, p *Pool, in <-chan In, workers int, out chan<- Out, fn func(context.Context, In) (Out, error), fail func(error)) {
+	sem := make(chan struct{}, workers)
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	for {
+		var item In
+		var ok bool
+		select {
+		case item, ok = <-in:
+			if !ok {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		running.Add(1)
+		var j job
+		j.fn = func() {
+			defer running.Done()
+			defer func() { <-sem }()
+			defer func() {
+				if r := recover(); r != nil {
+					fail(fmt.Errorf("task panicked: %v", r))
+					panic(r)
+				}
+			}()
+
+			v, err := fn(ctx, item)
+			if err != nil {
+				fail(err)
+				return
+			}
+			if out == nil {
+				return
+			}
+			select {
+			case out <- v:
+			case <-ctx.Done():
+			}
+		}
+		j.abort = func(err error) {
+			fail(err)
+			<-sem
+			running.Done()
+		}
+		err := p.submit(j)
+		if err != nil {
+			running.Done()
+			fail(err)
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package tinyPool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFanOutFanIn(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+	ctx := context.Background()
+
+	gen := func(from, to int) <-chan int {
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			for i := from; i < to; i++ {
+				ch <- i
+			}
+		}()
+		return ch
+	}
+
+	doubled := FanOut(ctx, p, FanIn(ctx, gen(0, 50), gen(50, 100)), func(_ context.Context, n int) int {
+		return n * 2
+	})
+
+	sum := 0
+	for n := range doubled {
+		sum += n
+	}
+	if sum != 9900 {
+		t.Fatalf("sum = %d, want 9900", sum)
+	}
+}
+
+func TestFanOutCancel(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	in := make(chan int)
+	out := FanOut(ctx, p, in, func(_ context.Context, n int) int { return n })
+	in <- 1
+	cancel()
+
+	for range out {
+	}
+}
+
+func TestConsume(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 1; i <= 100; i++ {
+			ch <- i
+		}
+	}()
+
+	var sum int64
+	err := Consume(context.Background(), p, ch, func(n int) {
+		atomic.AddInt64(&sum, int64(n))
+	})
+	if err != nil || sum != 5050 {
+		t.Fatalf("got %d, %v, want 5050", sum, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Consume(ctx, p, make(chan int), func(int) {}); err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestConsumeDropped(t *testing.T) {
+	p, _ := NewPool(1, WithQueueCap(1), WithRejectionPolicy(DiscardNewestPolicy{}))
+	defer p.Close()
+	p.Tune(1)
+
+	release := saturate(p)
+	defer release()
+	// fill the queue until the next task is discarded, and again once the
+	// dispatcher has taken the first queued task out of it
+	for p.Stats().Dropped == 0 {
+		_ = p.Submit(func() {})
+	}
+	time.Sleep(10 * time.Millisecond)
+	for p.Stats().Dropped == 1 {
+		_ = p.Submit(func() {})
+	}
+
+	ch := make(chan int, 2)
+	ch <- 1
+	ch <- 2
+	close(ch)
+	done := make(chan error)
+	go func() {
+		done <- Consume(context.Background(), p, ch, func(int) {})
+	}()
+	select {
+	case err := <-done:
+		if err != ErrTaskDropped {
+			t.Fatalf("Consume() = %v, want ErrTaskDropped", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Consume hangs on dropped tasks")
+	}
+}
+
+func TestConsumePanic(t *testing.T) {
+	p, _ := NewPool(2, WithPanicHandler(func(interface{}, []byte) {}))
+	defer p.Close()
+
+	ch := make(chan int, 1)
+	ch <- 1
+	close(ch)
+	if err := Consume(context.Background(), p, ch, func(int) { panic("boom") }); err == nil {
+		t.Fatal("Consume() = nil after a task panicked")
+	}
+}
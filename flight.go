@@ -0,0 +1,34 @@
+package tinyPool
+
+// SubmitShared submits fn to p unless a task submitted with the same key
+// is still running, in which case it returns the Future of that task.
+// All callers of a key share its single execution and its result. Once
+// the task has finished, the next call of the key submits fn again, unless
+// the result is still cached by WithResultCache.
+func SubmitShared[T any](p *Pool, key string, fn func() (T, error), opts ...TaskOption) *Future[T] {
+	f := newFuture[T](p)
+	if cached, ok := p.results.get(key); ok {
+		if val, ok := cached.(T); ok {
+			f.val = val
+			f.complete()
+			return f
+		}
+	}
+	if running, loaded := p.flights.LoadOrStore(key, f); loaded {
+		if shared, ok := running.(*Future[T]); ok {
+			return shared
+		}
+		f.err = ErrFlightType
+		f.complete()
+		return f
+	}
+
+	f.onDone = func() {
+		if f.err == nil {
+			p.results.put(key, f.val)
+		}
+		p.flights.CompareAndDelete(key, f)
+	}
+	f.submit(p, fn, opts)
+	return f
+}
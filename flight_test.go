@@ -0,0 +1,51 @@
+package tinyPool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSubmitShared(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	var runs int32
+	block := make(chan struct{})
+	fn := func() (int, error) {
+		<-block
+		return int(atomic.AddInt32(&runs, 1)), nil
+	}
+
+	futures := make([]*Future[int], 10)
+	for i := range futures {
+		futures[i] = SubmitShared(p, "user:1", fn)
+	}
+	close(block)
+
+	for _, f := range futures {
+		if n, err := f.Get(context.Background()); err != nil || n != 1 {
+			t.Fatalf("got %d, %v, want the shared result 1", n, err)
+		}
+	}
+
+	// the key is free again once the task has finished
+	n, _ := SubmitShared(p, "user:1", fn).Get(context.Background())
+	if n != 2 {
+		t.Fatalf("got %d, want a second run", n)
+	}
+}
+
+func TestSubmitSharedType(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	block := make(chan struct{})
+	defer close(block)
+	SubmitShared(p, "k", func() (int, error) { <-block; return 0, nil })
+
+	_, err := SubmitShared(p, "k", func() (string, error) { return "", nil }).Get(context.Background())
+	if err != ErrFlightType {
+		t.Fatalf("err = %v, want ErrFlightType", err)
+	}
+}
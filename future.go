@@ -0,0 +1,219 @@
+package tinyPool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Future is a handle to the result of a task submitted by SubmitResult.
+type Future[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+
+	// onDone is called right before the Future completes
+	onDone func()
+
+	// p runs the continuations added by Then and Catch
+	p *Pool
+
+	mu        sync.Mutex
+	completed bool
+	callbacks []func()
+}
+
+func newFuture[T any](p *Pool) *Future[T] {
+	return &Future[T]{done: make(chan struct{}), p: p}
+}
+
+// SubmitResult submits fn to p and returns a Future that holds its result.
+// If the task cannot be submitted, the Future completes with that error.
+func SubmitResult[T any](p *Pool, fn func() (T, error), opts ...TaskOption) *Future[T] {
+	f := newFuture[T](p)
+	f.submit(p, fn, opts)
+	return f
+}
+
+// submit submits fn to p, f holds its result.
+func (f *Future[T]) submit(p *Pool, fn func() (T, error), opts []TaskOption) {
+	j := newJob(opts)
+	j.fnErr = func() error {
+		defer func() {
+			if r := recover(); r != nil {
+				f.err = fmt.Errorf("task panicked: %v", r)
+				f.complete()
+				panic(r)
+			}
+		}()
+
+		f.val, f.err = fn()
+		return f.err
+	}
+	j.finish = f.complete
+	j.abort = func(err error) {
+		f.err = err
+		f.complete()
+	}
+
+	if err := p.submit(j); err != nil {
+		f.err = err
+		f.complete()
+	}
+}
+
+// complete marks f as finished and runs its callbacks.
+func (f *Future[T]) complete() {
+	if f.onDone != nil {
+		f.onDone()
+	}
+
+	f.mu.Lock()
+	f.completed = true
+	close(f.done)
+	callbacks := f.callbacks
+	f.callbacks = nil
+	f.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+// whenDone calls fn once f has completed, right away if it has already.
+func (f *Future[T]) whenDone(fn func()) {
+	f.mu.Lock()
+	if !f.completed {
+		f.callbacks = append(f.callbacks, fn)
+		f.mu.Unlock()
+		return
+	}
+	f.mu.Unlock()
+	fn()
+}
+
+// Then returns a Future of fn applied to the value of f. fn is submitted
+// to the pool of f once f has succeeded. If f fails, the returned Future
+// fails with the same error without running fn.
+func Then[T, U any](f *Future[T], fn func(T) (U, error), opts ...TaskOption) *Future[U] {
+	next := newFuture[U](f.p)
+	f.whenDone(func() {
+		if f.err != nil {
+			next.err = f.err
+			next.complete()
+			return
+		}
+		next.submit(f.p, func() (U, error) {
+			return fn(f.val)
+		}, opts)
+	})
+	return next
+}
+
+// Catch returns a Future which recovers from the failure of f. If f fails,
+// fn is submitted to the pool with its error and the returned Future
+// holds the result of fn. Otherwise it holds the value of f.
+func (f *Future[T]) Catch(fn func(error) (T, error), opts ...TaskOption) *Future[T] {
+	next := newFuture[T](f.p)
+	f.whenDone(func() {
+		if f.err == nil {
+			next.val = f.val
+			next.complete()
+			return
+		}
+		next.submit(f.p, func() (T, error) {
+			return fn(f.err)
+		}, opts)
+	})
+	return next
+}
+
+// Get waits for the task to finish and returns its result, or returns
+// ctx.Err() if ctx is done first.
+func (f *Future[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Done returns a channel that is closed when the task has finished.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// completions returns a channel which receives the index of each of
+// futures as it completes.
+func completions[T any](futures []*Future[T]) <-chan int {
+	// buffered for all, so the callbacks never block
+	done := make(chan int, len(futures))
+	for i, f := range futures {
+		i := i
+		f.whenDone(func() { done <- i })
+	}
+	return done
+}
+
+// All waits for all futures and returns their values in order. It returns
+// the first error of a future as soon as it occurs, or ctx.Err() if ctx is
+// done first.
+func All[T any](ctx context.Context, futures ...*Future[T]) ([]T, error) {
+	vals := make([]T, len(futures))
+	done := completions(futures)
+	for range futures {
+		select {
+		case i := <-done:
+			if err := futures[i].err; err != nil {
+				return nil, err
+			}
+			vals[i] = futures[i].val
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return vals, nil
+}
+
+// Any returns the value of the first future which succeeds. If all of
+// them fail, it returns their errors joined by errors.Join.
+func Any[T any](ctx context.Context, futures ...*Future[T]) (T, error) {
+	var zero T
+	errs := make([]error, 0, len(futures))
+	done := completions(futures)
+	for range futures {
+		select {
+		case i := <-done:
+			if err := futures[i].err; err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			return futures[i].val, nil
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+	if len(errs) == 0 {
+		return zero, errors.New("Any of no futures")
+	}
+	return zero, errors.Join(errs...)
+}
+
+// Race returns the result of the first future to complete, whether it
+// succeeded or not.
+func Race[T any](ctx context.Context, futures ...*Future[T]) (T, error) {
+	var zero T
+	if len(futures) == 0 {
+		return zero, errors.New("Race of no futures")
+	}
+
+	select {
+	case i := <-completions(futures):
+		return futures[i].val, futures[i].err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,186 @@
+package tinyPool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSubmitResult(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	f := SubmitResult(p, func() (int, error) {
+		return Fib(10), nil
+	})
+	v, err := f.Get(context.Background())
+	if err != nil || v != 55 {
+		t.Fatalf("Get() = %v, %v, want 55, nil", v, err)
+	}
+
+	errBad := errors.New("bad")
+	f = SubmitResult(p, func() (int, error) {
+		return 0, errBad
+	})
+	if _, err := f.Get(context.Background()); err != errBad {
+		t.Fatalf("Get() err = %v, want %v", err, errBad)
+	}
+}
+
+func TestFutureGetTimeout(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	block := make(chan struct{})
+	defer close(block)
+	f := SubmitResult(p, func() (string, error) {
+		<-block
+		return "late", nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := f.Get(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Get() err = %v, want deadline exceeded", err)
+	}
+}
+
+func TestFuturePanic(t *testing.T) {
+	p, _ := NewPool(1, WithPanicHandler(func(interface{}, []byte) {}))
+	defer p.Close()
+
+	f := SubmitResult(p, func() (int, error) {
+		panic("boom")
+	})
+	if _, err := f.Get(context.Background()); err == nil {
+		t.Fatal("Get() returned nil error for panicking task")
+	}
+}
+
+func TestSubmitResultClosed(t *testing.T) {
+	p, _ := NewPool(1)
+	p.Close()
+
+	f := SubmitResult(p, func() (int, error) {
+		return 1, nil
+	})
+	if _, err := f.Get(context.Background()); err == nil {
+		t.Fatal("Get() returned nil error for closed pool")
+	}
+}
+
+func TestAll(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	var futures []*Future[int]
+	for i := 0; i < 10; i++ {
+		i := i
+		futures = append(futures, SubmitResult(p, func() (int, error) {
+			return i, nil
+		}))
+	}
+	vals, err := All(context.Background(), futures...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range vals {
+		if v != i {
+			t.Fatalf("vals[%d] = %d", i, v)
+		}
+	}
+
+	errBad := errors.New("bad")
+	block := make(chan struct{})
+	defer close(block)
+	slow := SubmitResult(p, func() (int, error) { <-block; return 0, nil })
+	failed := SubmitResult(p, func() (int, error) { return 0, errBad })
+	if _, err := All(context.Background(), slow, failed); err != errBad {
+		t.Fatalf("err = %v, want %v without waiting for the slow future", err, errBad)
+	}
+}
+
+func TestAnyAndRace(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	errBad := errors.New("bad")
+	failed := SubmitResult(p, func() (int, error) { return 0, errBad })
+	slow := SubmitResult(p, func() (int, error) {
+		time.Sleep(20 * time.Millisecond)
+		return 2, nil
+	})
+
+	if _, err := Race(context.Background(), failed, slow); err != errBad {
+		t.Fatalf("Race err = %v, want %v", err, errBad)
+	}
+	if v, err := Any(context.Background(), failed, slow); err != nil || v != 2 {
+		t.Fatalf("Any = %d, %v, want 2", v, err)
+	}
+	if _, err := Any(context.Background(), failed); !errors.Is(err, errBad) {
+		t.Fatalf("Any of failures = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	block := make(chan struct{})
+	defer close(block)
+	never := SubmitResult(p, func() (int, error) { <-block; return 0, nil })
+	if _, err := Race(ctx, never); err != context.DeadlineExceeded {
+		t.Fatalf("Race err = %v, want deadline exceeded", err)
+	}
+}
+
+func TestRaceNoGoroutines(t *testing.T) {
+	f := newFuture[int](nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		_, _ = Race(ctx, f, f)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("%d goroutines left by Race on a pending future", n-before)
+	}
+
+	f.val = 1
+	f.complete()
+	if v, err := Race(context.Background(), f); err != nil || v != 1 {
+		t.Fatalf("Race = %d, %v, want 1", v, err)
+	}
+}
+
+func TestThenCatch(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	f := SubmitResult(p, func() (int, error) { return 20, nil })
+	s := Then(Then(f, func(n int) (int, error) {
+		return n + 1, nil
+	}), func(n int) (string, error) {
+		return fmt.Sprint(n * 2), nil
+	})
+	if v, err := s.Get(context.Background()); err != nil || v != "42" {
+		t.Fatalf("got %q, %v, want 42", v, err)
+	}
+
+	errBad := errors.New("bad")
+	failed := SubmitResult(p, func() (int, error) { return 0, errBad })
+	skipped := false
+	recovered := Then(failed, func(n int) (int, error) {
+		skipped = true
+		return n, nil
+	}).Catch(func(err error) (int, error) {
+		if err != errBad {
+			t.Errorf("caught %v, want %v", err, errBad)
+		}
+		return -1, nil
+	})
+	if v, err := recovered.Get(context.Background()); err != nil || v != -1 || skipped {
+		t.Fatalf("got %d, %v, want the value of Catch", v, err)
+	}
+}
@@ -0,0 +1,59 @@
+package tinyPool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// cpuSample is the CPU time the process used by a point in time.
+type cpuSample struct {
+	at   time.Time
+	used time.Duration
+	ok   bool
+}
+
+func sampleCPU() cpuSample {
+	used, ok := processCPUTime()
+	return cpuSample{at: time.Now(), used: used, ok: ok}
+}
+
+// govern throttles the tasks for the interval since the last call, see
+// WithCPUGovernor. While the CPU usage is above the threshold, the number
+// of tasks which may run at once halves, from the running workers at
+// first. Below it, the limit grows back by a quarter, and is lifted once
+// it reaches the capacity.
+func (p *engine[T]) govern() {
+	last := p.governor
+	p.governor = sampleCPU()
+	s := p.governor
+	elapsed := s.at.Sub(last.at)
+	if !s.ok || !last.ok || elapsed <= 0 {
+		return
+	}
+
+	usage := float64(s.used-last.used) / float64(elapsed) / float64(defaultSize())
+	limit := atomic.LoadInt32(&p.cpuLimit)
+	if usage > p.options.CPUThreshold {
+		if limit == 0 {
+			limit = p.Running()
+		}
+		if limit /= 2; limit < 1 {
+			limit = 1
+		}
+		if limit != atomic.LoadInt32(&p.cpuLimit) {
+			p.log(LevelInfo, "CPU governor throttled", "usage", usage, "limit", limit)
+			atomic.StoreInt32(&p.cpuLimit, limit)
+		}
+		return
+	}
+
+	if limit == 0 {
+		return
+	}
+	if limit += (limit + 3) / 4; limit >= p.Cap() {
+		limit = 0
+		p.log(LevelInfo, "CPU governor released", "usage", usage)
+	}
+	atomic.StoreInt32(&p.cpuLimit, limit)
+	p.signalLimit()
+}
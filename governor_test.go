@@ -0,0 +1,48 @@
+package tinyPool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithCPUGovernor(t *testing.T) {
+	if _, ok := processCPUTime(); !ok {
+		t.Skip("CPU time unknown on this platform")
+	}
+
+	p, _ := NewPool(4, WithCPUGovernor(0.1, 20*time.Millisecond))
+	defer p.Close()
+	p.Tune(4)
+
+	// spinning tasks keep the CPUs busy
+	stop := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		_ = p.Submit(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+			}
+		})
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Stats().Limit != 1 {
+		if time.Now().After(deadline) {
+			close(stop)
+			t.Fatalf("limit = %d under load, want 1", p.Stats().Limit)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(stop)
+
+	deadline = time.Now().Add(2 * time.Second)
+	for p.Stats().Limit != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("limit = %d when idle, want it lifted", p.Stats().Limit)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
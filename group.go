@@ -0,0 +1,105 @@
+package tinyPool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Group is a collection of tasks running on a pool, with the semantics of
+// errgroup.Group: the first error cancels the group context and is
+// returned by Wait.
+type Group struct {
+	p *Pool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wg sync.WaitGroup
+
+	errOnce sync.Once
+	err     error
+
+	// errs collects the error of every failed task for WaitAll
+	mu   sync.Mutex
+	errs []error
+}
+
+// Group returns a new Group whose context is derived from ctx.
+func (p *Pool) Group(ctx context.Context) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{p: p, ctx: ctx, cancel: cancel}
+}
+
+// Context returns the group context, which is canceled when a task of the
+// group fails or Wait returns.
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// Go runs fn on the pool. A task dropped before it runs, or refused by
+// the pool, fails the group with its error.
+func (g *Group) Go(fn func() error, opts ...TaskOption) {
+	g.wg.Add(1)
+
+	var err error
+	j := newJob(opts)
+	j.fnErr = func() error {
+		defer func() {
+			if r := recover(); r != nil {
+				g.fail(fmt.Errorf("task panicked: %v", r))
+				g.wg.Done()
+				panic(r)
+			}
+		}()
+
+		err = fn()
+		return err
+	}
+	j.finish = func() {
+		if err != nil {
+			g.fail(err)
+		}
+		g.wg.Done()
+	}
+	j.abort = func(err error) {
+		g.fail(err)
+		g.wg.Done()
+	}
+
+	if err := g.p.submit(j); err != nil {
+		g.fail(err)
+		g.wg.Done()
+	}
+}
+
+// Wait blocks until all tasks of the group have finished, then returns
+// the first error, if any.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+// WaitAll blocks until all tasks of the group have finished, then returns
+// the errors of all failed tasks joined by errors.Join.
+func (g *Group) WaitAll() error {
+	g.wg.Wait()
+	g.cancel()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return errors.Join(g.errs...)
+}
+
+func (g *Group) fail(err error) {
+	g.mu.Lock()
+	g.errs = append(g.errs, err)
+	g.mu.Unlock()
+
+	g.errOnce.Do(func() {
+		g.err = err
+		g.cancel()
+	})
+}
@@ -0,0 +1,109 @@
+package tinyPool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	var n int32
+	g := p.Group(context.Background())
+	for i := 0; i < 100; i++ {
+		g.Go(func() error {
+			atomic.AddInt32(&n, 1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+	if n != 100 {
+		t.Fatalf("%d of 100 tasks ran", n)
+	}
+}
+
+func TestGroupFirstError(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	errBad := errors.New("bad")
+	g := p.Group(context.Background())
+	g.Go(func() error {
+		return errBad
+	})
+	g.Go(func() error {
+		<-g.Context().Done()
+		return errors.New("canceled")
+	})
+
+	if err := g.Wait(); err != errBad {
+		t.Fatalf("Wait() = %v, want %v", err, errBad)
+	}
+}
+
+func TestGroupWaitAll(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	errs := []error{errors.New("a"), errors.New("b"), errors.New("c")}
+	g := p.Group(context.Background())
+	for _, err := range errs {
+		err := err
+		g.Go(func() error {
+			return err
+		})
+	}
+	g.Go(func() error {
+		return nil
+	})
+
+	err := g.WaitAll()
+	for _, e := range errs {
+		if !errors.Is(err, e) {
+			t.Fatalf("WaitAll() = %v, missing %v", err, e)
+		}
+	}
+}
+
+func TestGroupDropped(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g := p.Group(context.Background())
+	g.Go(func() error {
+		t.Error("dropped task ran")
+		return nil
+	}, WithContext(ctx))
+
+	done := make(chan error)
+	go func() { done <- g.Wait() }()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("Wait() = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait hangs on a dropped task")
+	}
+}
+
+func TestGroupPanic(t *testing.T) {
+	p, _ := NewPool(4, WithPanicHandler(func(interface{}, []byte) {}))
+	defer p.Close()
+
+	g := p.Group(context.Background())
+	g.Go(func() error {
+		panic("boom")
+	})
+	if err := g.Wait(); err == nil {
+		t.Fatal("Wait() = nil after a task panicked")
+	}
+}
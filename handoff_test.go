@@ -0,0 +1,60 @@
+package tinyPool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+// countingQueue counts the tasks pushed to it.
+type countingQueue struct {
+	Queue
+	pushes int64
+}
+
+func (q *countingQueue) Push(v interface{}) bool {
+	atomic.AddInt64(&q.pushes, 1)
+	return q.Queue.Push(v)
+}
+
+func TestHandoff(t *testing.T) {
+	q := &countingQueue{Queue: newMpscQueue()}
+	block := make(chan struct{})
+	p, _ := NewPoolWithFunc(4, func(chan struct{}) { <-block }, WithQueue(q))
+	defer p.Close()
+
+	// new workers get their first task straight from the submitter
+	for i := 0; i < 4; i++ {
+		_ = p.Invoke(block)
+	}
+	if n := atomic.LoadInt64(&q.pushes); n != 0 {
+		t.Fatalf("%d tasks queued while workers could start, want 0", n)
+	}
+
+	// the pool is at capacity and busy, so the next task is queued
+	_ = p.Invoke(block)
+	if n := atomic.LoadInt64(&q.pushes); n != 1 {
+		t.Fatalf("%d tasks queued with all workers busy, want 1", n)
+	}
+
+	close(block)
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandoffWorkerInit(t *testing.T) {
+	var ran int64
+	p, _ := NewPool(2, WithWorkerInit(func() error { return nil }))
+	defer p.Close()
+
+	for i := 0; i < 10; i++ {
+		_ = p.Submit(func() { atomic.AddInt64(&ran, 1) })
+	}
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran != 10 {
+		t.Fatalf("%d tasks ran, want 10", ran)
+	}
+}
@@ -0,0 +1,62 @@
+package tinyPool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat is passed to the tasks submitted by SubmitWithHeartbeat to
+// report that they are alive.
+type Heartbeat struct {
+	// last is the time of the latest beat in unix nanoseconds
+	last int64
+}
+
+// Beat reports that the task is making progress.
+func (hb *Heartbeat) Beat() {
+	atomic.StoreInt64(&hb.last, time.Now().UnixNano())
+}
+
+// Last returns the time of the latest beat, or of the start of the task
+// before its first beat.
+func (hb *Heartbeat) Last() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&hb.last))
+}
+
+// SubmitWithHeartbeat is like SubmitCancelable for tasks which call Beat
+// at least every interval while they run. A task missing its beats is
+// reported as Stalled in its status, and its context is canceled if
+// cancel is set. Beats are checked every interval, so a stall is noticed
+// up to two intervals after the latest beat.
+func (p *Pool) SubmitWithHeartbeat(task func(ctx context.Context, hb *Heartbeat) error, interval time.Duration, cancel bool, opts ...TaskOption) (*TaskHandle, error) {
+	ctx, cancelCtx := context.WithCancel(p.closing)
+	h := &TaskHandle{done: make(chan struct{}), ctx: ctx, cancel: cancelCtx, hb: &Heartbeat{}, p: p}
+	return p.submitTracked(h, func() error {
+		h.hb.Beat()
+		t := p.wheel.schedule(interval, interval, func() {
+			if time.Since(h.hb.Last()) < interval || !h.stall() {
+				return
+			}
+			p.log(LevelWarn, "task stopped beating", "task", h.status.Name, "last", h.hb.Last())
+			if cancel {
+				cancelCtx()
+			}
+		})
+		defer p.wheel.stop(t)
+
+		return task(ctx, h.hb)
+	}, append(opts[:len(opts):len(opts)], WithContext(ctx)))
+}
+
+// stall flags the running task as stalled. It returns false if the task
+// has been flagged before.
+func (h *TaskHandle) stall() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.status.Stalled || h.status.State != TaskRunning {
+		return false
+	}
+	h.status.Stalled = true
+	return true
+}
@@ -0,0 +1,38 @@
+package tinyPool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubmitWithHeartbeat(t *testing.T) {
+	p, _ := NewPool(2)
+	defer p.Close()
+
+	hung, _ := p.SubmitWithHeartbeat(func(ctx context.Context, hb *Heartbeat) error {
+		hb.Beat()
+		<-ctx.Done()
+		return ctx.Err()
+	}, 20*time.Millisecond, true)
+	alive, _ := p.SubmitWithHeartbeat(func(ctx context.Context, hb *Heartbeat) error {
+		for i := 0; i < 10; i++ {
+			time.Sleep(5 * time.Millisecond)
+			hb.Beat()
+		}
+		return nil
+	}, 20*time.Millisecond, true)
+
+	if err := hung.Wait(context.Background()); err != context.Canceled {
+		t.Fatalf("Wait() = %v, want canceled", err)
+	}
+	if s := hung.Status(); !s.Stalled || s.State != TaskCancelled || s.LastHeartbeat.Before(s.Started) {
+		t.Fatalf("status = %+v, want stalled", s)
+	}
+	if err := alive.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+	if s := alive.Status(); s.Stalled || s.State != TaskDone || !s.LastHeartbeat.After(s.Started) {
+		t.Fatalf("status = %+v, want done", s)
+	}
+}
@@ -0,0 +1,138 @@
+package tinyPool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultHedgePercentile = 0.95
+
+	// hedgeWindow is the number of recent latencies a hedging threshold
+	// is derived from, hedgeMinSamples the number needed to hedge at all
+	hedgeWindow     = 128
+	hedgeMinSamples = 10
+)
+
+// SubmitHedged submits fn to p and returns a Future of its result. If fn
+// has not finished when earlier hedged tasks of the same name had reached
+// their HedgePercentile latency, a second attempt is started and the
+// Future takes the result of whichever finishes first. The context of
+// the other attempt is canceled then, fn should give up when it is.
+func SubmitHedged[T any](p *Pool, fn func(ctx context.Context) (T, error), opts ...TaskOption) *Future[T] {
+	f := newFuture[T](p)
+	ctx, cancel := context.WithCancel(p.ctx)
+
+	var once sync.Once
+	settle := func(val T, err error) {
+		once.Do(func() {
+			f.val, f.err = val, err
+			f.complete()
+			cancel()
+		})
+	}
+
+	w := p.latencies(newJob(opts).name)
+	attempt := func() {
+		defer func() {
+			if r := recover(); r != nil {
+				var zero T
+				settle(zero, fmt.Errorf("task panicked: %v", r))
+				panic(r)
+			}
+		}()
+
+		if ctx.Err() != nil {
+			// the other attempt has won already
+			return
+		}
+		start := time.Now()
+		val, err := fn(ctx)
+		if ctx.Err() == nil {
+			w.add(time.Since(start))
+		}
+		settle(val, err)
+	}
+
+	// live counts the attempts not dropped, the Future fails with the
+	// error of a dropped attempt only once no other one can settle it
+	var live int32
+	submit := func() error {
+		atomic.AddInt32(&live, 1)
+		j := newJob(opts)
+		j.fn = attempt
+		j.abort = func(err error) {
+			if atomic.AddInt32(&live, -1) == 0 {
+				var zero T
+				settle(zero, err)
+			}
+		}
+
+		err := p.submit(j)
+		if err != nil {
+			atomic.AddInt32(&live, -1)
+		}
+		return err
+	}
+
+	if err := submit(); err != nil {
+		var zero T
+		settle(zero, err)
+		return f
+	}
+
+	if d, ok := w.percentile(p.options.HedgePercentile); ok {
+		p.wheel.schedule(d, 0, func() {
+			if ctx.Err() == nil {
+				_ = submit()
+			}
+		})
+	}
+	return f
+}
+
+func (p *Pool) latencies(name string) *latencyWindow {
+	if w, ok := p.hedges.Load(name); ok {
+		return w.(*latencyWindow)
+	}
+	w, _ := p.hedges.LoadOrStore(name, &latencyWindow{})
+	return w.(*latencyWindow)
+}
+
+// latencyWindow keeps the latest latencies of a kind of task.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples [hedgeWindow]time.Duration
+	n       int
+	next    int
+}
+
+func (w *latencyWindow) add(d time.Duration) {
+	w.mu.Lock()
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % hedgeWindow
+	if w.n < hedgeWindow {
+		w.n++
+	}
+	w.mu.Unlock()
+}
+
+// percentile returns the q-th percentile of the window. It reports false
+// while the window has too few samples.
+func (w *latencyWindow) percentile(q float64) (time.Duration, bool) {
+	w.mu.Lock()
+	if w.n < hedgeMinSamples {
+		w.mu.Unlock()
+		return 0, false
+	}
+	sorted := make([]time.Duration, w.n)
+	copy(sorted, w.samples[:w.n])
+	w.mu.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(q*float64(len(sorted)-1))], true
+}
@@ -0,0 +1,78 @@
+package tinyPool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubmitHedged(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	fast := func(ctx context.Context) (int, error) {
+		time.Sleep(time.Millisecond)
+		return 1, nil
+	}
+	for i := 0; i < hedgeMinSamples; i++ {
+		if _, err := SubmitHedged(p, fast, WithTaskName("rpc")).Get(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the first attempt hangs until canceled, the hedge answers
+	var attempts, canceled int32
+	start := time.Now()
+	f := SubmitHedged(p, func(ctx context.Context) (int, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-ctx.Done()
+			atomic.AddInt32(&canceled, 1)
+			return 0, ctx.Err()
+		}
+		return 2, nil
+	}, WithTaskName("rpc"))
+
+	n, err := f.Get(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("got %d, %v, want the result of the hedge", n, err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("hedge started after %v", d)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if atomic.LoadInt32(&canceled) != 1 {
+		t.Fatal("losing attempt was not canceled")
+	}
+}
+
+func TestSubmitHedgedDropped(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f := SubmitHedged(p, func(ctx context.Context) (int, error) {
+		return 1, nil
+	}, WithContext(ctx))
+
+	get, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	if _, err := f.Get(get); err != context.Canceled {
+		t.Fatalf("Get() = %v, want context.Canceled of the dropped task", err)
+	}
+}
+
+func TestLatencyWindow(t *testing.T) {
+	var w latencyWindow
+	if _, ok := w.percentile(0.5); ok {
+		t.Fatal("percentile of an empty window")
+	}
+	for i := 1; i <= 100; i++ {
+		w.add(time.Duration(i))
+	}
+	if d, _ := w.percentile(0.95); d != 95 {
+		t.Fatalf("p95 = %v, want 95", d)
+	}
+}
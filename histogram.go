@@ -0,0 +1,161 @@
+package tinyPool
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// histogramBounds are the upper bounds of the task duration buckets,
+// doubling from 100µs to about 13s.
+var histogramBounds = func() []time.Duration {
+	bounds := make([]time.Duration, 18)
+	for i := range bounds {
+		bounds[i] = 100 * time.Microsecond << i
+	}
+	return bounds
+}()
+
+// histogram counts task durations in the buckets of histogramBounds, the
+// last count is for longer tasks.
+type histogram struct {
+	counts [19]uint64
+	sum    int64
+}
+
+// add records the duration of a task.
+func (h *histogram) add(d time.Duration) {
+	i := 0
+	for i < len(histogramBounds) && d > histogramBounds[i] {
+		i++
+	}
+	atomic.AddUint64(&h.counts[i], 1)
+	atomic.AddInt64(&h.sum, int64(d))
+}
+
+// observe records the duration of item, which started at start.
+func (p *engine[T]) observe(item T, start time.Time) {
+	d := time.Since(start)
+	p.durations.add(d)
+	p.latencies.add(d)
+	if h := p.options.Hooks.OnComplete; h != nil {
+		h(p.name(item), d)
+	}
+	if observers := p.observers.Load(); observers != nil {
+		for _, fn := range *observers {
+			fn(d)
+		}
+	}
+}
+
+// ObserveDurations registers fn to be called with the duration of every
+// task finished from now on. fn runs on the worker, so it should be fast.
+func (p *engine[T]) ObserveDurations(fn func(d time.Duration)) {
+	for {
+		old := p.observers.Load()
+		var observers []func(time.Duration)
+		if old != nil {
+			observers = append(observers, *old...)
+		}
+		observers = append(observers, fn)
+		if p.observers.CompareAndSwap(old, &observers) {
+			return
+		}
+	}
+}
+
+// DurationHistogram is a snapshot of the durations of the tasks a pool
+// has run.
+type DurationHistogram struct {
+	// Bounds are the upper bounds of the buckets. Counts holds the number
+	// of tasks in each bucket, its last element counts the tasks longer
+	// than the last bound.
+	Bounds []time.Duration
+	Counts []uint64
+
+	// Count is the number of tasks and Sum their total duration.
+	Count uint64
+	Sum   time.Duration
+}
+
+// Durations returns a snapshot of the durations of the tasks run so far.
+func (p *engine[T]) Durations() DurationHistogram {
+	h := DurationHistogram{
+		Bounds: histogramBounds,
+		Counts: make([]uint64, len(p.durations.counts)),
+		Sum:    time.Duration(atomic.LoadInt64(&p.durations.sum)),
+	}
+	for i := range h.Counts {
+		h.Counts[i] = atomic.LoadUint64(&p.durations.counts[i])
+		h.Count += h.Counts[i]
+	}
+	return h
+}
+
+// latencyHistogram is a log-linear histogram of durations in nanoseconds
+// in the manner of HDR histograms. Each power of two is split into
+// latencySubBuckets buckets, so quantiles are off by at most 1/8.
+type latencyHistogram struct {
+	counts [latencyBuckets]uint64
+}
+
+const (
+	latencySubBits    = 3
+	latencySubBuckets = 1 << latencySubBits
+
+	// durations below 2*latencySubBuckets ns get a bucket each
+	latencyBuckets = (64-latencySubBits)*latencySubBuckets + latencySubBuckets
+)
+
+func latencyIndex(d time.Duration) int {
+	v := uint64(d)
+	if d < 0 {
+		v = 0
+	}
+	if v < 2*latencySubBuckets {
+		return int(v)
+	}
+	shift := bits.Len64(v) - latencySubBits - 1
+	return shift*latencySubBuckets + int(v>>shift)
+}
+
+// latencyValue returns the middle of bucket i.
+func latencyValue(i int) time.Duration {
+	if i < 2*latencySubBuckets {
+		return time.Duration(i)
+	}
+	shift := i/latencySubBuckets - 1
+	low := uint64(i%latencySubBuckets+latencySubBuckets) << shift
+	return time.Duration(low + (uint64(1)<<shift)/2)
+}
+
+func (h *latencyHistogram) add(d time.Duration) {
+	atomic.AddUint64(&h.counts[latencyIndex(d)], 1)
+}
+
+// quantiles returns the durations at the quantiles qs, which must be in
+// increasing order. They are zero while the histogram is empty.
+func (h *latencyHistogram) quantiles(qs ...float64) []time.Duration {
+	var counts [latencyBuckets]uint64
+	var total uint64
+	for i := range counts {
+		counts[i] = atomic.LoadUint64(&h.counts[i])
+		total += counts[i]
+	}
+
+	out := make([]time.Duration, len(qs))
+	if total == 0 {
+		return out
+	}
+
+	var seen uint64
+	next := 0
+	for i := 0; i < latencyBuckets && next < len(qs); i++ {
+		seen += counts[i]
+		for next < len(qs) && float64(seen) >= qs[next]*float64(total) {
+			out[next] = latencyValue(i)
+			next++
+		}
+	}
+	return out
+}
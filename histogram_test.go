@@ -0,0 +1,66 @@
+package tinyPool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDurations(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	_ = p.Submit(func() {})
+	_ = p.Submit(func() { time.Sleep(2 * time.Millisecond) })
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	h := p.Durations()
+	if h.Count != 2 || h.Sum < 2*time.Millisecond {
+		t.Fatalf("histogram = %+v, want 2 tasks of at least 2ms in total", h)
+	}
+	if h.Counts[0] != 1 {
+		t.Fatalf("counts = %v, want the fast task in the first bucket", h.Counts)
+	}
+}
+
+func TestObserveDurations(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	observed := make(chan time.Duration, 1)
+	p.ObserveDurations(func(d time.Duration) {
+		observed <- d
+	})
+	_ = p.Submit(func() { time.Sleep(time.Millisecond) })
+
+	select {
+	case d := <-observed:
+		if d < time.Millisecond {
+			t.Fatalf("observed %v, want at least 1ms", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("duration not observed")
+	}
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	for _, d := range []time.Duration{0, 1, 15, 16, 17, 31, 32, 1000, time.Millisecond, time.Hour} {
+		v := latencyValue(latencyIndex(d))
+		if diff := v - d; diff < -d/8-1 || diff > d/8+1 {
+			t.Fatalf("bucket of %v has value %v", d, v)
+		}
+	}
+
+	var h latencyHistogram
+	for i := 1; i <= 1000; i++ {
+		h.add(time.Duration(i) * time.Microsecond)
+	}
+	q := h.quantiles(0.5, 0.99)
+	for i, want := range []time.Duration{500 * time.Microsecond, 990 * time.Microsecond} {
+		if diff := q[i] - want; diff < -want/8 || diff > want/8 {
+			t.Fatalf("quantile %d = %v, want about %v", i, q[i], want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package tinyPool
+
+import "time"
+
+// Hooks are callbacks called at the stages of the life of a task or a
+// worker, for metrics, logging or auditing. Any of them may be nil. Task
+// hooks are called with the name given by WithTaskName, which is empty
+// for PoolWithFunc. Hooks run on the goroutine passing the stage, so they
+// should be fast.
+type Hooks struct {
+	// OnSubmit is called when the pool accepts a task.
+	OnSubmit func(name string)
+
+	// OnStart is called when a worker starts a task, with the time it
+	// waited since it was submitted.
+	OnStart func(name string, wait time.Duration)
+
+	// OnComplete is called when a task has finished, also when it
+	// panicked, with the time it ran.
+	OnComplete func(name string, d time.Duration)
+
+	// OnReject is called when the pool refuses a task, with the error
+	// returned to the submitter.
+	OnReject func(name string, err error)
+
+	// OnDrop is called when a task is dropped by CoDel, DiscardOldestPolicy
+	// or DiscardNewestPolicy, with the time it was queued.
+	OnDrop func(name string, wait time.Duration)
+
+	// OnPanic is called with the value recovered from a panicking task,
+	// before the panic handler.
+	OnPanic func(name string, r interface{})
+
+	// OnWorkerStart is called on a new worker before it takes a task,
+	// OnWorkerStop when it exits, with the ID shown by DumpWorkers.
+	OnWorkerStart func(id int)
+	OnWorkerStop  func(id int)
+}
@@ -0,0 +1,85 @@
+package tinyPool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithHooks(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string][]string)
+	record := func(stage, name string) {
+		mu.Lock()
+		calls[stage] = append(calls[stage], name)
+		mu.Unlock()
+	}
+
+	p, _ := NewPool(1, WithQueueCap(1), WithNonblocking(true), WithPanicHandler(func(interface{}, []byte) {}),
+		WithHooks(Hooks{
+			OnSubmit:   func(name string) { record("submit", name) },
+			OnStart:    func(name string, _ time.Duration) { record("start", name) },
+			OnComplete: func(name string, _ time.Duration) { record("complete", name) },
+			OnReject:   func(name string, _ error) { record("reject", name) },
+			OnPanic:    func(name string, _ interface{}) { record("panic", name) },
+		}))
+	p.Tune(1)
+
+	block := make(chan struct{})
+	_ = p.Submit(func() { <-block }, WithTaskName("a"))
+	time.Sleep(10 * time.Millisecond)
+	_ = p.Submit(func() { panic("boom") }, WithTaskName("b"))
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = p.Submit(func() {}, WithTaskName("c"))
+	}
+	if err != ErrQueueFull {
+		t.Fatalf("err = %v, want ErrQueueFull", err)
+	}
+	close(block)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fmt.Sprint(calls["reject"], calls["panic"]); got != "[c] [b]" {
+		t.Fatalf("reject and panic hooks = %s, want [c] [b]", got)
+	}
+	accepted := len(calls["submit"])
+	for _, stage := range []string{"submit", "start", "complete"} {
+		names := calls[stage]
+		if len(names) != accepted || names[0] != "a" || names[1] != "b" {
+			t.Fatalf("%s hooks = %v", stage, names)
+		}
+	}
+}
+
+func TestWorkerHooks(t *testing.T) {
+	var mu sync.Mutex
+	started := make(map[int]bool)
+	var stopped []int
+	p, _ := NewPool(4, WithPreAlloc(2), WithHooks(Hooks{
+		OnWorkerStart: func(id int) {
+			mu.Lock()
+			started[id] = true
+			mu.Unlock()
+		},
+		OnWorkerStop: func(id int) {
+			mu.Lock()
+			stopped = append(stopped, id)
+			mu.Unlock()
+		},
+	}))
+
+	if len(p.DumpWorkers()) != 2 {
+		t.Fatalf("workers = %+v", p.DumpWorkers())
+	}
+	p.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(started) != 2 || len(stopped) != 2 || !started[stopped[0]] || !started[stopped[1]] {
+		t.Fatalf("started %v, stopped %v", started, stopped)
+	}
+}
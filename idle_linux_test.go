@@ -0,0 +1,47 @@
+//go:build linux
+
+package tinyPool
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func cpuTime(t *testing.T) time.Duration {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		t.Fatal(err)
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
+
+// idleChildEnv is set for the test process measuring an idle pool.
+const idleChildEnv = "TINYPOOL_IDLE_CHILD"
+
+func TestIdlePoolUsesNoCPU(t *testing.T) {
+	if os.Getenv(idleChildEnv) == "" {
+		// measure in a process of its own, as the CPU time of this one
+		// includes the goroutines the other tests leave winding down
+		cmd := exec.Command(os.Args[0], "-test.run=^TestIdlePoolUsesNoCPU$", "-test.count=1")
+		cmd.Env = append(os.Environ(), idleChildEnv+"=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v\n%s", err, out)
+		}
+		return
+	}
+
+	p, _ := NewPool(4, WithPreAlloc(4))
+	defer p.Close()
+	_ = p.Submit(func() {})
+
+	runtime.GC()
+	before := cpuTime(t)
+	time.Sleep(200 * time.Millisecond)
+	if used := cpuTime(t) - before; used > time.Millisecond {
+		t.Fatalf("idle pool used %v of CPU in 200ms", used)
+	}
+}
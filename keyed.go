@@ -0,0 +1,215 @@
+package tinyPool
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// keyedTask is a task waiting for the earlier tasks of its key.
+type keyedTask struct {
+	fn   func()
+	opts []TaskOption
+}
+
+// laneID identifies a lane of keyed tasks: a single key, or a shard of
+// keys when KeyShards is set.
+type laneID struct {
+	key   string
+	shard int
+}
+
+// keyLanes limits the number of running tasks of each lane.
+type keyLanes struct {
+	mu    sync.Mutex
+	lanes map[laneID]*lane
+
+	// limits holds the concurrency set by SetKeyLimit, lanes missing from
+	// it run one task at a time
+	limits map[laneID]int
+}
+
+// lane holds the running count and the waiting tasks of a lane.
+type lane struct {
+	running int
+	waiting []keyedTask
+}
+
+// lane returns the lane of key.
+func (p *Pool) lane(key string) laneID {
+	if p.options.KeyShards <= 0 {
+		return laneID{key: key, shard: -1}
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return laneID{shard: jumpHash(h.Sum64(), p.options.KeyShards)}
+}
+
+// jumpHash maps key onto one of n buckets with the jump consistent hash of
+// Lamping and Veach, so changing n moves as few keys as possible.
+func jumpHash(key uint64, n int) int {
+	var b, j int64 = -1, 0
+	for j < int64(n) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// SetKeyLimit lets up to n tasks of key run at once, the default is one.
+// Waiting tasks of the key still start in submission order. With KeyShards
+// set, the limit applies to the shard of key.
+func (p *Pool) SetKeyLimit(key string, n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	id := p.lane(key)
+	p.keys.mu.Lock()
+	if p.keys.limits == nil {
+		p.keys.limits = make(map[laneID]int)
+	}
+	p.keys.limits[id] = n
+	p.keys.mu.Unlock()
+
+	// start the tasks let in by a raised limit
+	for p.nextKeyed(id, false) {
+	}
+}
+
+// keyLimit returns the concurrency of lane id, p.keys.mu must be held.
+func (p *Pool) keyLimit(id laneID) int {
+	if n, ok := p.keys.limits[id]; ok {
+		return n
+	}
+	return 1
+}
+
+// SubmitKeyed submits a task which runs after all tasks submitted earlier
+// with the same key have finished, or once fewer of them run than the
+// limit set by SetKeyLimit. Tasks of different keys run in parallel,
+// unless KeyShards is set and the keys hash onto the same shard.
+func (p *Pool) SubmitKeyed(key string, task func(), opts ...TaskOption) error {
+	if task == nil {
+		return nil
+	}
+	if p.IsClosed() {
+		return ErrPoolClosed
+	}
+
+	id := p.lane(key)
+	t := keyedTask{fn: task, opts: opts}
+	p.keys.mu.Lock()
+	if p.keys.lanes == nil {
+		p.keys.lanes = make(map[laneID]*lane)
+	}
+	l := p.keys.lanes[id]
+	if l == nil {
+		l = &lane{}
+		p.keys.lanes[id] = l
+	}
+	if len(l.waiting) > 0 || l.running >= p.keyLimit(id) {
+		// waiting tasks are pending, so Shutdown waits for them
+		atomic.AddInt64(&p.pending, 1)
+		l.waiting = append(l.waiting, t)
+		p.keys.mu.Unlock()
+		return nil
+	}
+	l.running++
+	p.keys.mu.Unlock()
+
+	err := p.submitKeyed(id, t, false)
+	if err != nil {
+		// pass the slot on to the tasks queued in the meantime
+		p.nextKeyed(id, true)
+	}
+	return err
+}
+
+// submitKeyed submits t, which holds a slot of lane id. The slot is given
+// up when t finishes or is dropped. A task which waited in the lane was
+// accepted already, so it is still queued while the pool shuts down.
+func (p *Pool) submitKeyed(id laneID, t keyedTask, waited bool) error {
+	j := newJob(t.opts)
+	j.fn = func() {
+		defer p.nextKeyed(id, true)
+		t.fn()
+	}
+	abort := j.abort
+	j.abort = func(err error) {
+		if abort != nil {
+			abort(err)
+		}
+		p.nextKeyed(id, true)
+	}
+	if waited && p.IsClosed() {
+		return p.resubmit(j)
+	}
+	return p.submit(j)
+}
+
+// resubmit queues j, a task accepted before the pool closed, so Shutdown
+// still runs it. It fails with ErrPoolClosed once the pool has quit.
+func (p *Pool) resubmit(j job) error {
+	select {
+	case <-p.quitSig:
+		return ErrPoolClosed
+	default:
+	}
+	j.submitted = time.Now()
+	j.trace = newTaskTrace(j.name)
+	return p.place(j)
+}
+
+// release gives up a running slot of lane l, p.keys.mu must be held.
+func (p *Pool) release(id laneID, l *lane) {
+	l.running--
+	if l.running == 0 && len(l.waiting) == 0 {
+		delete(p.keys.lanes, id)
+	}
+}
+
+// nextKeyed submits the next task waiting in lane id, if the lane has a
+// free slot. done releases the slot of a finished task first. It reports
+// whether a task was submitted.
+func (p *Pool) nextKeyed(id laneID, done bool) bool {
+	p.keys.mu.Lock()
+	l := p.keys.lanes[id]
+	if l == nil {
+		p.keys.mu.Unlock()
+		return false
+	}
+	if done {
+		l.running--
+	}
+	if len(l.waiting) == 0 || l.running >= p.keyLimit(id) {
+		if l.running == 0 && len(l.waiting) == 0 {
+			delete(p.keys.lanes, id)
+		}
+		p.keys.mu.Unlock()
+		return false
+	}
+	next := l.waiting[0]
+	l.waiting[0] = keyedTask{}
+	l.waiting = l.waiting[1:]
+	l.running++
+	p.keys.mu.Unlock()
+
+	err := p.submitKeyed(id, next, true)
+	// the task is counted again by the submission, if it succeeded
+	atomic.AddInt64(&p.pending, -1)
+	if err == nil {
+		return true
+	}
+
+	// the task was refused, drop the rest of the lane
+	p.keys.mu.Lock()
+	atomic.AddInt64(&p.pending, -int64(len(l.waiting)))
+	l.waiting = nil
+	p.release(id, l)
+	p.keys.mu.Unlock()
+	return false
+}
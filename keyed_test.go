@@ -0,0 +1,192 @@
+package tinyPool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubmitKeyed(t *testing.T) {
+	p, _ := NewPool(8)
+	defer p.Close()
+
+	const keys, perKey = 4, 100
+	var mu sync.Mutex
+	seen := make(map[string][]int)
+	var running [keys]int32
+	var wg sync.WaitGroup
+
+	wg.Add(keys * perKey)
+	for i := 0; i < perKey; i++ {
+		for k := 0; k < keys; k++ {
+			i, k := i, k
+			key := fmt.Sprint("key", k)
+			_ = p.SubmitKeyed(key, func() {
+				defer wg.Done()
+				if atomic.AddInt32(&running[k], 1) != 1 {
+					t.Errorf("tasks of %s overlap", key)
+				}
+				time.Sleep(10 * time.Microsecond)
+				mu.Lock()
+				seen[key] = append(seen[key], i)
+				mu.Unlock()
+				atomic.AddInt32(&running[k], -1)
+			})
+		}
+	}
+	wg.Wait()
+
+	for key, order := range seen {
+		for i, n := range order {
+			if n != i {
+				t.Fatalf("tasks of %s ran out of order: %v", key, order)
+			}
+		}
+	}
+}
+
+func TestWithKeyShards(t *testing.T) {
+	p, _ := NewPool(8, WithKeyShards(2))
+	defer p.Close()
+
+	var running, overlap int32
+	var wg sync.WaitGroup
+	wg.Add(100)
+	for i := 0; i < 100; i++ {
+		_ = p.SubmitKeyed(fmt.Sprint("key", i), func() {
+			defer wg.Done()
+			if atomic.AddInt32(&running, 1) > 2 {
+				atomic.StoreInt32(&overlap, 1)
+			}
+			time.Sleep(10 * time.Microsecond)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+	wg.Wait()
+
+	if overlap != 0 {
+		t.Fatal("more keyed tasks ran at once than there are shards")
+	}
+}
+
+func TestJumpHash(t *testing.T) {
+	moved := 0
+	for k := uint64(0); k < 10000; k++ {
+		b := jumpHash(k, 10)
+		if b < 0 || b >= 10 {
+			t.Fatalf("jumpHash(%d, 10) = %d", k, b)
+		}
+		if jumpHash(k, 11) != b {
+			moved++
+		}
+	}
+	// about 1/11 of the keys move to the new bucket
+	if moved > 1500 {
+		t.Fatalf("%d of 10000 keys moved when adding a bucket", moved)
+	}
+}
+
+func TestSetKeyLimit(t *testing.T) {
+	p, _ := NewPool(8)
+	defer p.Close()
+	p.SetKeyLimit("tenant", 2)
+
+	var running, peak int32
+	var wg sync.WaitGroup
+	wg.Add(50)
+	for i := 0; i < 50; i++ {
+		_ = p.SubmitKeyed("tenant", func() {
+			defer wg.Done()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+	wg.Wait()
+
+	if peak != 2 {
+		t.Fatalf("peak concurrency of key = %d, want 2", peak)
+	}
+}
+
+func TestSubmitKeyedShutdown(t *testing.T) {
+	p, _ := NewPool(2)
+
+	var ran int32
+	for i := 0; i < 5; i++ {
+		_ = p.SubmitKeyed("key", func() {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&ran, 1)
+		})
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran != 5 {
+		t.Fatalf("%d of 5 keyed tasks ran before Shutdown returned", ran)
+	}
+}
+
+func TestSubmitKeyedDropped(t *testing.T) {
+	p, _ := NewPool(2)
+	defer p.Close()
+
+	block := make(chan struct{})
+	done := make(chan struct{})
+	_ = p.SubmitKeyed("key", func() { <-block })
+	_ = p.SubmitKeyed("key", func() {
+		t.Error("expired task ran")
+	}, WithDeadline(time.Now()))
+	_ = p.SubmitKeyed("key", func() { close(done) })
+	close(block)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("lane stalled after a dropped task")
+	}
+}
+
+func TestSubmitKeyedRefused(t *testing.T) {
+	// the first estimate holds the submission of the first task, then
+	// refuses it
+	estimating := make(chan struct{})
+	refuse := make(chan struct{})
+	var calls int32
+	p, _ := NewPool(2, WithSLO(time.Second, EstimatorFunc(func(Load) time.Duration {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(estimating)
+			<-refuse
+			return time.Hour
+		}
+		return 0
+	})))
+	defer p.Close()
+
+	refused := make(chan error)
+	go func() {
+		refused <- p.SubmitKeyed("key", func() {})
+	}()
+	<-estimating
+	ran := make(chan struct{})
+	_ = p.SubmitKeyed("key", func() { close(ran) })
+	close(refuse)
+
+	if err := <-refused; err != ErrWouldMissSLO {
+		t.Fatalf("first task = %v, want ErrWouldMissSLO", err)
+	}
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("task queued behind a refused one never ran")
+	}
+}
@@ -0,0 +1,226 @@
+package tinyPool
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConcurrencyLimit is an adaptive limit of the number of tasks running at
+// once, which probes how much load the downstream of the tasks takes by
+// their latency. It is independent of the number of workers: a worker
+// holds its task until the limit lets it run.
+type ConcurrencyLimit interface {
+	// Limit returns the current number of tasks which may run at once,
+	// at least 1.
+	Limit() int
+
+	// Observe records a task which ran for rtt, while inflight tasks ran
+	// including itself.
+	Observe(rtt time.Duration, inflight int)
+}
+
+// AIMDLimit is a ConcurrencyLimit which grows by one for every task
+// finished within Threshold while the limit is at least half used, and
+// backs off by a factor of Backoff for every slower one.
+type AIMDLimit struct {
+	Threshold time.Duration
+
+	// Backoff is the factor the limit shrinks by, 0.9 if 0.
+	Backoff float64
+
+	mu       sync.Mutex
+	limit    float64
+	min, max float64
+}
+
+// NewAIMDLimit returns an AIMDLimit starting at initial, kept between min
+// and max, which backs off on tasks slower than threshold.
+func NewAIMDLimit(initial, min, max int, threshold time.Duration) *AIMDLimit {
+	l := &AIMDLimit{Threshold: threshold}
+	l.min, l.max = limitBounds(min, max)
+	l.limit = clampLimit(float64(initial), l.min, l.max)
+	return l
+}
+
+// Limit implements ConcurrencyLimit.
+func (l *AIMDLimit) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Observe implements ConcurrencyLimit.
+func (l *AIMDLimit) Observe(rtt time.Duration, inflight int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch {
+	case rtt > l.Threshold:
+		backoff := l.Backoff
+		if backoff <= 0 || backoff >= 1 {
+			backoff = 0.9
+		}
+		l.limit = clampLimit(l.limit*backoff, l.min, l.max)
+	case float64(inflight)*2 >= l.limit:
+		// an idle limit proves nothing about the downstream
+		l.limit = clampLimit(l.limit+1, l.min, l.max)
+	}
+}
+
+// GradientLimit is a ConcurrencyLimit following the ratio of the lowest
+// latency seen, taken as the latency without queueing downstream, to the
+// latest one. While they match, it grows by the square root of the limit,
+// as queueing room; as latency rises, the limit shrinks by up to a half.
+type GradientLimit struct {
+	// Smoothing is the weight of a new limit against the current one, 0.2
+	// if 0.
+	Smoothing float64
+
+	mu       sync.Mutex
+	limit    float64
+	min, max float64
+	minRTT   time.Duration
+}
+
+// NewGradientLimit returns a GradientLimit starting at initial, kept
+// between min and max.
+func NewGradientLimit(initial, min, max int) *GradientLimit {
+	l := &GradientLimit{}
+	l.min, l.max = limitBounds(min, max)
+	l.limit = clampLimit(float64(initial), l.min, l.max)
+	return l
+}
+
+// Limit implements ConcurrencyLimit.
+func (l *GradientLimit) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Observe implements ConcurrencyLimit.
+func (l *GradientLimit) Observe(rtt time.Duration, inflight int) {
+	if rtt <= 0 {
+		rtt = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.minRTT == 0 || rtt < l.minRTT {
+		l.minRTT = rtt
+	}
+	if float64(inflight)*2 < l.limit {
+		// latency under a light load says nothing about a higher limit
+		return
+	}
+
+	gradient := math.Max(0.5, math.Min(1, float64(l.minRTT)/float64(rtt)))
+	next := l.limit*gradient + math.Sqrt(l.limit)
+	smoothing := l.Smoothing
+	if smoothing <= 0 || smoothing > 1 {
+		smoothing = 0.2
+	}
+	l.limit = clampLimit(l.limit*(1-smoothing)+next*smoothing, l.min, l.max)
+}
+
+// limitBounds returns the bounds of a limit from min and max, with min at
+// least 1 and max no less than min, 0 max meaning no upper bound.
+func limitBounds(min, max int) (float64, float64) {
+	if min < 1 {
+		min = 1
+	}
+	if max <= 0 {
+		return float64(min), math.MaxInt32
+	}
+	if max < min {
+		max = min
+	}
+	return float64(min), float64(max)
+}
+
+func clampLimit(limit, min, max float64) float64 {
+	return math.Max(min, math.Min(max, limit))
+}
+
+// concurrencyLimit returns the current concurrency limit, at least 1.
+func (p *engine[T]) concurrencyLimit() int32 {
+	if n := p.options.ConcurrencyLimit.Limit(); n > 1 {
+		return int32(n)
+	}
+	return 1
+}
+
+// overLimit reports whether a task submitted now would take the pending
+// tasks past the concurrency limit and is to be rejected, see
+// WithConcurrencyLimit.
+func (p *engine[T]) overLimit() bool {
+	if p.options.ConcurrencyLimit == nil || !p.options.RejectOverLimit {
+		return false
+	}
+	return atomic.LoadInt64(&p.pending) >= int64(p.concurrencyLimit())
+}
+
+// runLimit returns the number of tasks which may run at once, the lower of
+// the concurrency limit and the limit of the CPU governor.
+func (p *engine[T]) runLimit() int32 {
+	limit := int32(math.MaxInt32)
+	if p.options.ConcurrencyLimit != nil {
+		limit = p.concurrencyLimit()
+	}
+	if g := atomic.LoadInt32(&p.cpuLimit); g > 0 && g < limit {
+		limit = g
+	}
+	return limit
+}
+
+// admit waits until the concurrency limit and the CPU governor let another
+// task run. It reports false if the pool has neither, else release must
+// follow.
+func (p *engine[T]) admit() bool {
+	if p.options.ConcurrencyLimit == nil && p.options.CPUThreshold <= 0 {
+		return false
+	}
+
+	for {
+		n := atomic.LoadInt32(&p.inflight)
+		limit := p.runLimit()
+		if n >= limit {
+			select {
+			case <-p.limitSig:
+				continue
+			case <-p.ctx.Done():
+				// only when the pool is force closed, run the task anyway
+				atomic.AddInt32(&p.inflight, 1)
+				return true
+			}
+		}
+		if atomic.CompareAndSwapInt32(&p.inflight, n, n+1) {
+			if n+1 < limit {
+				// room for another waiting worker
+				p.signalLimit()
+			}
+			return true
+		}
+	}
+}
+
+// releaseLimit ends a task admitted at start, letting the concurrency
+// limit, if any, observe its latency.
+func (p *engine[T]) releaseLimit(start time.Time) {
+	inflight := atomic.AddInt32(&p.inflight, -1) + 1
+	if l := p.options.ConcurrencyLimit; l != nil {
+		l.Observe(time.Since(start), int(inflight))
+	}
+	p.signalLimit()
+}
+
+// signalLimit wakes a worker waiting for the concurrency limit, if any.
+func (p *engine[T]) signalLimit() {
+	select {
+	case p.limitSig <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,113 @@
+package tinyPool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAIMDLimit(t *testing.T) {
+	l := NewAIMDLimit(10, 2, 12, 10*time.Millisecond)
+
+	// fast tasks under load grow the limit up to max
+	for i := 0; i < 5; i++ {
+		l.Observe(time.Millisecond, 10)
+	}
+	if n := l.Limit(); n != 12 {
+		t.Fatalf("limit = %d after fast tasks, want 12", n)
+	}
+
+	// a mostly idle limit doesn't grow
+	l = NewAIMDLimit(10, 2, 20, 10*time.Millisecond)
+	l.Observe(time.Millisecond, 1)
+	if n := l.Limit(); n != 10 {
+		t.Fatalf("limit = %d after an idle task, want 10", n)
+	}
+
+	// slow ones back off down to min
+	l.Observe(20*time.Millisecond, 10)
+	if n := l.Limit(); n != 9 {
+		t.Fatalf("limit = %d after a slow task, want 9", n)
+	}
+	for i := 0; i < 50; i++ {
+		l.Observe(20*time.Millisecond, 10)
+	}
+	if n := l.Limit(); n != 2 {
+		t.Fatalf("limit = %d after slow tasks, want 2", n)
+	}
+}
+
+func TestGradientLimit(t *testing.T) {
+	l := NewGradientLimit(10, 1, 100)
+	for i := 0; i < 20; i++ {
+		l.Observe(time.Millisecond, l.Limit())
+	}
+	grown := l.Limit()
+	if grown <= 10 {
+		t.Fatalf("limit = %d at the lowest latency, want it grown", grown)
+	}
+
+	// latency rising to 4 times the lowest one halves the new limits
+	for i := 0; i < 20; i++ {
+		l.Observe(4*time.Millisecond, l.Limit())
+	}
+	if n := l.Limit(); n >= grown {
+		t.Fatalf("limit = %d at 4 times the latency, want below %d", n, grown)
+	}
+}
+
+func TestWithConcurrencyLimit(t *testing.T) {
+	p, _ := NewPool(8, WithConcurrencyLimit(NewAIMDLimit(2, 2, 2, time.Second), false))
+	defer p.Close()
+
+	var running, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		_ = p.Submit(func() {
+			defer wg.Done()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&peak)
+				if n <= max || atomic.CompareAndSwapInt32(&peak, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+	wg.Wait()
+
+	if peak != 2 {
+		t.Fatalf("%d tasks ran at once, want 2", peak)
+	}
+	if s := p.Stats(); s.Limit != 2 || s.Inflight != 0 {
+		t.Fatalf("limit %d with %d in flight, want 2 with 0", s.Limit, s.Inflight)
+	}
+}
+
+func TestConcurrencyLimitReject(t *testing.T) {
+	p, _ := NewPool(4, WithConcurrencyLimit(NewAIMDLimit(2, 2, 2, time.Second), true))
+	defer p.Close()
+
+	block := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		if err := p.Submit(func() { <-block }); err != nil {
+			t.Fatalf("submit %d failed: %v", i, err)
+		}
+	}
+	if err := p.Submit(func() {}); err != ErrLimitExceeded {
+		t.Fatalf("submit past the limit = %v, want ErrLimitExceeded", err)
+	}
+	if p.TrySubmit(func() {}) {
+		t.Fatal("TrySubmit past the limit succeeded")
+	}
+
+	close(block)
+	time.Sleep(20 * time.Millisecond)
+	if err := p.Submit(func() {}); err != nil {
+		t.Fatalf("submit under the limit failed: %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package tinyPool
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestWithLockOSThread(t *testing.T) {
+	var initTid int
+	p, _ := NewPool(1, WithLockOSThread(), WithWorkerInit(func() error {
+		initTid = syscall.Gettid()
+		return nil
+	}))
+	defer p.Close()
+	p.Tune(1)
+
+	for i := 0; i < 20; i++ {
+		tid := make(chan int)
+		_ = p.Submit(func() {
+			// give the scheduler a chance to move the goroutine
+			for j := 0; j < 100; j++ {
+				syscall.Getpid()
+			}
+			tid <- syscall.Gettid()
+		})
+		if got := <-tid; got != initTid {
+			t.Fatalf("task ran on thread %d, worker was locked to %d", got, initTid)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package tinyPool
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogLevel is the severity of a log record.
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// Logger receives the internal events of a pool, such as workers starting
+// and stopping, rejected tasks and panics. args are alternating keys and
+// values. A pool without a logger stays silent.
+type Logger interface {
+	Log(level LogLevel, msg string, args ...interface{})
+}
+
+// SlogLogger returns a Logger writing to l.
+func SlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) Log(level LogLevel, msg string, args ...interface{}) {
+	s.l.Log(context.Background(), slogLevel(level), msg, args...)
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// log writes a record to the logger of the pool, if it has one.
+func (p *engine[T]) log(level LogLevel, msg string, args ...interface{}) {
+	if l := p.options.Logger; l != nil {
+		l.Log(level, msg, args...)
+	}
+}
@@ -0,0 +1,66 @@
+package tinyPool
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordLogger struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (l *recordLogger) Log(level LogLevel, msg string, args ...interface{}) {
+	l.mu.Lock()
+	l.msgs = append(l.msgs, msg)
+	l.mu.Unlock()
+}
+
+func (l *recordLogger) has(msg string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, m := range l.msgs {
+		if m == msg {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWithLogger(t *testing.T) {
+	l := &recordLogger{}
+	p, _ := NewPool(1, WithLogger(l), WithExpiry(10*time.Millisecond))
+
+	done := make(chan struct{})
+	_ = p.Submit(func() {
+		defer close(done)
+		panic("boom")
+	})
+	<-done
+	time.Sleep(100 * time.Millisecond)
+	p.Close()
+	_ = p.Submit(func() {})
+
+	for _, msg := range []string{"worker started", "task panicked", "purging idle worker", "worker stopped", "task rejected"} {
+		if !l.has(msg) {
+			t.Errorf("no %q record in %v", msg, l.msgs)
+		}
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := SlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	l.Log(LevelDebug, "hidden")
+	l.Log(LevelWarn, "task rejected", "task", "a", "err", ErrQueueFull)
+
+	if got := buf.String(); strings.Contains(got, "hidden") ||
+		!strings.Contains(got, `level=WARN msg="task rejected" task=a err="task queue is full"`) {
+		t.Fatalf("log = %q", got)
+	}
+}
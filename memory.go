@@ -0,0 +1,77 @@
+package tinyPool
+
+import (
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// heapMetric is the memory occupied by live objects and dead ones not yet
+// swept, the heap usage the memory watermark is checked against.
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// heapSampling is how long a reading of the heap usage is reused, reading
+// it on every submission would cost more than most tasks.
+const heapSampling = 10 * time.Millisecond
+
+// heapGauge caches the heap usage of the process for all pools.
+type heapGauge struct {
+	// at is when bytes were read in Unix nanoseconds
+	at    int64
+	bytes uint64
+
+	// mu is held by the goroutine reading the metric
+	mu     sync.Mutex
+	sample [1]metrics.Sample
+}
+
+var heapUsage = &heapGauge{sample: [1]metrics.Sample{{Name: heapMetric}}}
+
+// load returns the heap usage, read again if the last reading is older
+// than heapSampling. While another goroutine reads it, the last reading
+// is returned.
+func (g *heapGauge) load() uint64 {
+	now := time.Now().UnixNano()
+	if now-atomic.LoadInt64(&g.at) < int64(heapSampling) || !g.mu.TryLock() {
+		return atomic.LoadUint64(&g.bytes)
+	}
+	defer g.mu.Unlock()
+
+	metrics.Read(g.sample[:])
+	var bytes uint64
+	if v := g.sample[0].Value; v.Kind() == metrics.KindUint64 {
+		bytes = v.Uint64()
+	}
+	atomic.StoreUint64(&g.bytes, bytes)
+	atomic.StoreInt64(&g.at, now)
+	return bytes
+}
+
+// admitMemory holds a submission while the heap usage is above the memory
+// watermark, or fails it with ErrMemoryWatermark if RejectOverMemory is
+// set or wait is false. It fails with ErrPoolClosed if the pool closes
+// while the submission waits.
+func (p *engine[T]) admitMemory(wait bool) error {
+	watermark := p.options.MemoryWatermark
+	if watermark == 0 || heapUsage.load() <= watermark {
+		return nil
+	}
+	if !wait || p.options.RejectOverMemory {
+		return ErrMemoryWatermark
+	}
+
+	timer := time.NewTimer(heapSampling)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if heapUsage.load() <= watermark {
+				return nil
+			}
+			timer.Reset(heapSampling)
+		case <-p.closing.Done():
+			return ErrPoolClosed
+		}
+	}
+}
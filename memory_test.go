@@ -0,0 +1,55 @@
+package tinyPool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeapUsage(t *testing.T) {
+	if n := heapUsage.load(); n == 0 {
+		t.Fatal("heap usage = 0")
+	}
+}
+
+func TestMemoryWatermarkReject(t *testing.T) {
+	p, _ := NewPool(1, WithMemoryWatermark(1, true))
+	defer p.Close()
+
+	if err := p.Submit(func() {}); err != ErrMemoryWatermark {
+		t.Fatalf("submit above the watermark = %v, want ErrMemoryWatermark", err)
+	}
+	if p.TrySubmit(func() {}) {
+		t.Fatal("TrySubmit above the watermark succeeded")
+	}
+
+	q, _ := NewPool(1, WithMemoryWatermark(1<<50, true))
+	defer q.Close()
+	if err := q.Submit(func() {}); err != nil {
+		t.Fatalf("submit below the watermark failed: %v", err)
+	}
+}
+
+func TestMemoryWatermarkDefer(t *testing.T) {
+	p, _ := NewPool(1, WithMemoryWatermark(1, false))
+
+	done := make(chan error)
+	go func() { done <- p.Submit(func() {}) }()
+	select {
+	case err := <-done:
+		t.Fatalf("submit above the watermark returned %v, want it to wait", err)
+	case <-time.After(30 * time.Millisecond):
+	}
+	if p.TrySubmit(func() {}) {
+		t.Fatal("TrySubmit above the watermark succeeded")
+	}
+
+	p.Close()
+	select {
+	case err := <-done:
+		if err != ErrPoolClosed {
+			t.Fatalf("waiting submit = %v after close, want ErrPoolClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("submit still waiting after close")
+	}
+}
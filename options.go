@@ -0,0 +1,692 @@
+package tinyPool
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Option represents the optional function.
+type Option func(opts *Options)
+
+// Options contains all options which will be applied when instantiating a pool.
+type Options struct {
+	// Name tells the pool apart in logs and profiles.
+	Name string
+
+	// PprofLabels runs every task with the pprof labels "pool", set to
+	// Name, and "tag", so CPU profiles attribute samples to them.
+	PprofLabels bool
+
+	// ExpiryDuration is the period of time without new tasks after which the
+	// pool stops one worker.
+	ExpiryDuration time.Duration
+
+	// ScaleToZero stops the dispatcher too, once the purge has stopped all
+	// workers, so an idle pool runs no goroutine at all. The next task
+	// starts it again. It has no effect with DisablePurge, MinWorkers or
+	// CoreSize. With it, the dispatcher is only started with the first
+	// task.
+	ScaleToZero bool
+
+	// DisablePurge keeps started workers alive until the pool is closed,
+	// ExpiryDuration is ignored then.
+	DisablePurge bool
+
+	// EarliestDeadlineFirst makes the queue hand out the task with the
+	// earliest deadline first, instead of the oldest one.
+	EarliestDeadlineFirst bool
+
+	// FairQueueing interleaves the queued tasks of the producers created
+	// by Pool.Producer in proportion to their weights, instead of handing
+	// them out in submission order. EarliestDeadlineFirst is ignored then.
+	FairQueueing bool
+
+	// Queue buffers tasks while all workers are busy. Pool keeps tasks of
+	// normal priority in it.
+	Queue Queue
+
+	// DispatchShards spreads the queued tasks over this many queues, each
+	// with its own dispatcher, so submitters on many cores don't contend
+	// on one queue. Scheduling by priority, deadline or producer holds
+	// within each shard only. It is ignored with a custom Queue.
+	DispatchShards int
+
+	// WorkStealing gives every worker a deque for the tasks submitted
+	// while all workers are busy, instead of the shared queue, and lets
+	// idle workers steal from the deques of busy ones. Tasks in the deques
+	// skip the scheduling by priority, deadline or producer.
+	WorkStealing bool
+
+	// shardQueue creates the queues of the shards past the first one, nil
+	// with a custom Queue
+	shardQueue func() Queue
+
+	// PanicHandler is used to handle panics from each task with the
+	// recovered value and the stack trace of the panicking goroutine.
+	// If nil, panics are written to the Logger, or the standard logger
+	// without one. Either way the worker survives the panic.
+	PanicHandler func(interface{}, []byte)
+
+	// ErrorHandler is called with the error of every task that returns
+	// one, such as tasks submitted by SubmitErr.
+	ErrorHandler func(error)
+
+	// DeadLetterHandler receives the tasks which failed on their last
+	// attempt.
+	DeadLetterHandler func(DeadLetter)
+
+	// HedgePercentile is the percentile of the latency of earlier runs
+	// after which SubmitHedged starts a second attempt, 0.95 by default.
+	HedgePercentile float64
+
+	// Hooks are called at the stages of the life of every task.
+	Hooks Hooks
+
+	// SlowTaskHandler is called with every task still running after
+	// SlowTaskThreshold, 0 disables it.
+	SlowTaskThreshold time.Duration
+	SlowTaskHandler   func(SlowTask)
+
+	// StuckWorkerLimit is how long a task may run before its worker is
+	// reported as stuck, 0 disables the check. With ReplaceStuckWorkers
+	// another worker is started in place of each stuck one, until its
+	// task returns.
+	StuckWorkerLimit    time.Duration
+	ReplaceStuckWorkers bool
+
+	// LockOSThread wires every worker to an OS thread for its lifetime,
+	// for cgo libraries and other code depending on thread-local state.
+	// WorkerInit runs on that thread too.
+	LockOSThread bool
+
+	// WorkerInit is called by every worker when it starts. A worker whose
+	// init fails exits without taking a task. WorkerTeardown is called
+	// when a worker that started exits, also by expiry.
+	WorkerInit     func() error
+	WorkerTeardown func()
+
+	// MaxTasksPerWorker is the number of tasks after which a worker is
+	// replaced by a new one, 0 means no limit.
+	MaxTasksPerWorker int
+
+	// WorkerStateFactory creates the value of the WorkerState of each
+	// worker when it starts, WorkerStateRelease releases it when the
+	// worker exits.
+	WorkerStateFactory func() interface{}
+	WorkerStateRelease func(interface{})
+
+	// Logger receives the internal events of the pool, nil keeps it
+	// silent.
+	Logger Logger
+
+	// KeyShards hashes the keys of SubmitKeyed onto this many serial
+	// lanes, 0 gives every key a lane of its own.
+	KeyShards int
+
+	// TagLimits caps the number of running tasks of each tag set with
+	// WithTag. Tags missing from it are unlimited.
+	TagLimits map[string]int
+
+	// BreakerThreshold is the number of consecutive failures of a tag
+	// which opens its circuit for BreakerCooldown, 0 disables the circuit
+	// breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// ResultCacheSize is the max number of results of SubmitShared kept
+	// for ResultCacheTTL, 0 disables the cache.
+	ResultCacheSize int
+	ResultCacheTTL  time.Duration
+
+	// PreAlloc is the number of workers started when the pool is created.
+	PreAlloc int
+
+	// MinWorkers is the number of workers the pool keeps alive when idle.
+	// They are started with the pool.
+	MinWorkers int
+
+	// CoreSize is the number of workers submitters start for their tasks,
+	// and which the pool keeps alive when idle. Past it, up to the
+	// capacity, workers are only started while the ScalePolicy says so,
+	// by default while more than GrowThreshold tasks are queued, and stop
+	// again after ExpiryDuration idle. 0 starts workers up to the capacity
+	// right away, unless there is a ScalePolicy.
+	CoreSize      int
+	GrowThreshold int
+
+	// ScalePolicy decides when submitters start workers past CoreSize.
+	ScalePolicy ScalePolicy
+
+	// AutoscaleMax enables the autoscaler, which tunes the capacity of the
+	// pool between AutoscaleMin and AutoscaleMax every AutoscaleInterval,
+	// one second by default. It sizes the pool for the measured arrival
+	// rate and task duration, plus the workers which get through the
+	// queue within an interval, and grows it by a quarter while tasks
+	// wait for longer than AutoscaleTargetWait to start.
+	AutoscaleMin        int
+	AutoscaleMax        int
+	AutoscaleInterval   time.Duration
+	AutoscaleTargetWait time.Duration
+
+	// BurstExtra is the number of workers the pool starts past its
+	// capacity while it is saturated, for BurstDuration. Then the surplus
+	// stops, and the next burst comes BurstDuration later at the soonest.
+	// 0 disables bursts.
+	BurstExtra    int
+	BurstDuration time.Duration
+
+	// ConcurrencyLimit adapts the number of tasks running at once to the
+	// latency of the tasks, to protect what they call from overload.
+	// Workers hold their task while the limit is reached, unless
+	// RejectOverLimit is set, which makes submissions fail with
+	// ErrLimitExceeded once the pending tasks reach the limit.
+	ConcurrencyLimit ConcurrencyLimit
+	RejectOverLimit  bool
+
+	// CPUThreshold enables the CPU governor, which checks the CPU usage of
+	// the process every CPUInterval, one second by default. While it is
+	// above CPUThreshold, a fraction of the CPUs the process may use, the
+	// governor halves the number of tasks which may run at once, and lets
+	// it grow back by a quarter once it is below, so a background pool
+	// yields to the other work of the process. The CPU usage is only known
+	// on Unix.
+	CPUThreshold float64
+	CPUInterval  time.Duration
+
+	// MemoryWatermark is the heap usage in bytes above which submissions
+	// wait until it falls, or fail with ErrMemoryWatermark if
+	// RejectOverMemory is set, so a growing backlog cannot exhaust the
+	// memory of the process. TrySubmit never waits. 0 means no watermark.
+	MemoryWatermark  uint64
+	RejectOverMemory bool
+
+	// CoDelTarget enables controlled delay queue management: once queued
+	// tasks wait for longer than CoDelTarget for a whole CoDelInterval,
+	// 100ms by default, the pool drops tasks as it takes them out of the
+	// queue, with ErrTaskDropped, more often the longer the waits stay
+	// above the target, so the backlog cannot grow forever under
+	// persistent overload. See Hooks.OnDrop.
+	CoDelTarget   time.Duration
+	CoDelInterval time.Duration
+
+	// SLO is the latency a task should finish within, queue wait
+	// included. Submissions which SLOEstimator, QueueEstimator by default,
+	// predicts to take longer fail with ErrWouldMissSLO, so callers can
+	// degrade gracefully instead of waiting in vain. 0 means no SLO.
+	SLO          time.Duration
+	SLOEstimator Estimator
+
+	// BacklogHigh and BacklogLow are watermarks of the number of queued
+	// tasks, so producers can pause their intake before submissions fail:
+	// OnBacklogHigh is called with the queued tasks once they reach
+	// BacklogHigh, then OnBacklogLow once they fall to BacklogLow. The
+	// callbacks run on the goroutine queueing or taking out the task, so
+	// they should be fast. 0 BacklogHigh disables them.
+	BacklogHigh   int
+	BacklogLow    int
+	OnBacklogHigh func(queued int)
+	OnBacklogLow  func(queued int)
+
+	// QueueCap is the max number of tasks waiting in the queue,
+	// 0 means the queue is unbounded.
+	QueueCap int
+
+	// When Nonblocking is true, Submit fails with ErrQueueFull instead of
+	// waiting for a free slot in a full queue.
+	Nonblocking bool
+
+	// MaxBlockingTasks is the max number of goroutines blocked on Submit
+	// waiting for a queue slot, 0 means no limit.
+	MaxBlockingTasks int
+
+	// RateLimit caps the number of task starts per second, 0 means no
+	// limit. RateBurst is the number of tasks which may start at once.
+	RateLimit rate.Limit
+	RateBurst int
+
+	// RejectionPolicy decides what happens to a task submitted while the
+	// queue is full. It defaults to BlockPolicy, or AbortPolicy in
+	// nonblocking mode.
+	RejectionPolicy RejectionPolicy
+
+	// OnQueueFull is called with every task the full queue rejects, after
+	// the rejection policy failed it, so it can be logged, counted or
+	// diverted. It runs on the submitting goroutine.
+	OnQueueFull func(t RejectedTask)
+}
+
+func loadOptions(options ...Option) *Options {
+	opts := &Options{
+		ExpiryDuration: expireTimeout,
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	if opts.ExpiryDuration <= 0 {
+		opts.ExpiryDuration = expireTimeout
+	}
+	if opts.HedgePercentile <= 0 || opts.HedgePercentile >= 1 {
+		opts.HedgePercentile = defaultHedgePercentile
+	}
+	if opts.AutoscaleMax > 0 && opts.AutoscaleInterval <= 0 {
+		opts.AutoscaleInterval = time.Second
+	}
+	if opts.BacklogHigh > 0 {
+		if opts.BacklogLow < 0 {
+			opts.BacklogLow = 0
+		} else if opts.BacklogLow >= opts.BacklogHigh {
+			opts.BacklogLow = opts.BacklogHigh - 1
+		}
+	}
+	if opts.CoDelTarget > 0 && opts.CoDelInterval <= 0 {
+		opts.CoDelInterval = 100 * time.Millisecond
+	}
+	if opts.CPUThreshold > 0 && opts.CPUInterval <= 0 {
+		opts.CPUInterval = time.Second
+	}
+	if opts.Queue == nil {
+		opts.Queue = newQueue(opts)
+		opts.shardQueue = func() Queue { return newQueue(opts) }
+	}
+	if opts.RejectionPolicy == nil {
+		if opts.Nonblocking {
+			opts.RejectionPolicy = AbortPolicy{}
+		} else {
+			opts.RejectionPolicy = BlockPolicy{}
+		}
+	}
+	return opts
+}
+
+// newQueue returns a task queue for the scheduling set in opts.
+func newQueue(opts *Options) Queue {
+	if opts.FairQueueing {
+		return newFairQueue()
+	}
+	if opts.EarliestDeadlineFirst {
+		return newEDFQueue()
+	}
+	return newMpscQueue()
+}
+
+// WithOptions accepts the whole options config.
+func WithOptions(options Options) Option {
+	return func(opts *Options) {
+		*opts = options
+	}
+}
+
+// WithName sets up the name of the pool.
+func WithName(name string) Option {
+	return func(opts *Options) {
+		opts.Name = name
+	}
+}
+
+// WithPprofLabels labels the goroutine of every task with the pool name
+// and the task tag while it runs.
+func WithPprofLabels() Option {
+	return func(opts *Options) {
+		opts.PprofLabels = true
+	}
+}
+
+// WithExpiryDuration sets up the interval time of cleaning up idle workers.
+func WithExpiryDuration(expiryDuration time.Duration) Option {
+	return func(opts *Options) {
+		opts.ExpiryDuration = expiryDuration
+	}
+}
+
+// WithExpiry is an alias for WithExpiryDuration.
+func WithExpiry(expiry time.Duration) Option {
+	return WithExpiryDuration(expiry)
+}
+
+// WithDisablePurge indicates whether to turn off cleaning up idle workers,
+// so started workers stay alive until the pool is closed.
+func WithDisablePurge(disable bool) Option {
+	return func(opts *Options) {
+		opts.DisablePurge = disable
+	}
+}
+
+// WithDispatchShards sets up the number of queues and dispatchers tasks
+// are spread over.
+func WithDispatchShards(n int) Option {
+	return func(opts *Options) {
+		opts.DispatchShards = n
+	}
+}
+
+// WithWorkStealing hands queued tasks to the deques of the workers, see
+// Options.WorkStealing.
+func WithWorkStealing() Option {
+	return func(opts *Options) {
+		opts.WorkStealing = true
+	}
+}
+
+// WithScaleToZero stops the dispatcher of an idle pool without workers,
+// see Options.ScaleToZero.
+func WithScaleToZero() Option {
+	return func(opts *Options) {
+		opts.ScaleToZero = true
+	}
+}
+
+// WithEarliestDeadlineFirst schedules queued tasks by their deadline set
+// with WithDeadline, the most urgent first.
+func WithEarliestDeadlineFirst() Option {
+	return func(opts *Options) {
+		opts.EarliestDeadlineFirst = true
+	}
+}
+
+// WithFairQueueing shares the workers among producers by their weights.
+func WithFairQueueing() Option {
+	return func(opts *Options) {
+		opts.FairQueueing = true
+	}
+}
+
+// WithQueue sets up the queue which buffers pending tasks.
+func WithQueue(q Queue) Option {
+	return func(opts *Options) {
+		opts.Queue = q
+	}
+}
+
+// WithPanicHandler sets up panic handler.
+func WithPanicHandler(panicHandler func(interface{}, []byte)) Option {
+	return func(opts *Options) {
+		opts.PanicHandler = panicHandler
+	}
+}
+
+// WithErrorHandler sets up the handler of errors returned by tasks.
+func WithErrorHandler(errorHandler func(error)) Option {
+	return func(opts *Options) {
+		opts.ErrorHandler = errorHandler
+	}
+}
+
+// WithDeadLetterHandler sets up the handler of tasks which still fail
+// after all their attempts.
+func WithDeadLetterHandler(handler func(DeadLetter)) Option {
+	return func(opts *Options) {
+		opts.DeadLetterHandler = handler
+	}
+}
+
+// WithHedgePercentile sets the latency percentile, between 0 and 1, after
+// which a hedged task is duplicated.
+func WithHedgePercentile(q float64) Option {
+	return func(opts *Options) {
+		opts.HedgePercentile = q
+	}
+}
+
+// WithHooks sets up the callbacks called at the stages of every task.
+func WithHooks(hooks Hooks) Option {
+	return func(opts *Options) {
+		opts.Hooks = hooks
+	}
+}
+
+// WithSlowTaskThreshold calls handler with each task that has been
+// running for d, while it goes on running.
+func WithSlowTaskThreshold(d time.Duration, handler func(SlowTask)) Option {
+	return func(opts *Options) {
+		opts.SlowTaskThreshold = d
+		opts.SlowTaskHandler = handler
+	}
+}
+
+// WithStuckWorkerLimit reports workers whose task runs for longer than
+// limit as stuck, and starts a replacement for each of them if replace is
+// set, so hung tasks don't take away capacity.
+func WithStuckWorkerLimit(limit time.Duration, replace bool) Option {
+	return func(opts *Options) {
+		opts.StuckWorkerLimit = limit
+		opts.ReplaceStuckWorkers = replace
+	}
+}
+
+// WithLockOSThread runs every worker locked to its own OS thread.
+func WithLockOSThread() Option {
+	return func(opts *Options) {
+		opts.LockOSThread = true
+	}
+}
+
+// WithWorkerInit sets up the function called by every worker when it
+// starts, before it takes a task.
+func WithWorkerInit(init func() error) Option {
+	return func(opts *Options) {
+		opts.WorkerInit = init
+	}
+}
+
+// WithWorkerTeardown sets up the function called by every worker when it
+// exits.
+func WithWorkerTeardown(teardown func()) Option {
+	return func(opts *Options) {
+		opts.WorkerTeardown = teardown
+	}
+}
+
+// WithMaxTasksPerWorker replaces each worker by a new goroutine after it
+// ran n tasks, which bounds the memory one goroutine may pile up.
+func WithMaxTasksPerWorker(n int) Option {
+	return func(opts *Options) {
+		opts.MaxTasksPerWorker = n
+	}
+}
+
+// WithWorkerState gives every worker a value created by factory, which
+// is passed to the tasks submitted by SubmitWithState. release, if not
+// nil, is called with the value when the worker exits.
+func WithWorkerState(factory func() interface{}, release func(interface{})) Option {
+	return func(opts *Options) {
+		opts.WorkerStateFactory = factory
+		opts.WorkerStateRelease = release
+	}
+}
+
+// WithLogger sets up the logger of internal events, see SlogLogger.
+func WithLogger(logger Logger) Option {
+	return func(opts *Options) {
+		opts.Logger = logger
+	}
+}
+
+// WithKeyShards hashes the keys of SubmitKeyed onto n serial lanes.
+func WithKeyShards(n int) Option {
+	return func(opts *Options) {
+		opts.KeyShards = n
+	}
+}
+
+// WithTagLimits caps the number of running tasks of each tag.
+func WithTagLimits(limits map[string]int) Option {
+	return func(opts *Options) {
+		opts.TagLimits = limits
+	}
+}
+
+// WithCircuitBreaker fails tasks of a tag fast with ErrCircuitOpen for
+// cooldown once threshold tasks of the tag failed in a row.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(opts *Options) {
+		opts.BreakerThreshold = threshold
+		opts.BreakerCooldown = cooldown
+	}
+}
+
+// WithResultCache caches the successful results of SubmitShared for ttl,
+// evicting the least recently used of more than size results.
+func WithResultCache(size int, ttl time.Duration) Option {
+	return func(opts *Options) {
+		opts.ResultCacheSize = size
+		opts.ResultCacheTTL = ttl
+	}
+}
+
+// WithPreAlloc starts n workers when the pool is created.
+func WithPreAlloc(n int) Option {
+	return func(opts *Options) {
+		opts.PreAlloc = n
+	}
+}
+
+// WithCoreSize starts workers past core, up to the capacity of the pool,
+// only while more than threshold tasks are queued.
+func WithCoreSize(core, threshold int) Option {
+	return func(opts *Options) {
+		opts.CoreSize = core
+		opts.GrowThreshold = threshold
+	}
+}
+
+// WithScalePolicy sets up when submitters start workers past the core size,
+// see QueueScalePolicy.
+func WithScalePolicy(policy ScalePolicy) Option {
+	return func(opts *Options) {
+		opts.ScalePolicy = policy
+	}
+}
+
+// WithAutoscale tunes the capacity of the pool between min and max every
+// interval, so tasks wait no longer than targetWait to start, see
+// Options.AutoscaleMax.
+func WithAutoscale(min, max int, interval, targetWait time.Duration) Option {
+	return func(opts *Options) {
+		opts.AutoscaleMin = min
+		opts.AutoscaleMax = max
+		opts.AutoscaleInterval = interval
+		opts.AutoscaleTargetWait = targetWait
+	}
+}
+
+// WithBurst lets the saturated pool run extra workers past its capacity
+// for d, see Options.BurstExtra.
+func WithBurst(extra int, d time.Duration) Option {
+	return func(opts *Options) {
+		opts.BurstExtra = extra
+		opts.BurstDuration = d
+	}
+}
+
+// WithConcurrencyLimit bounds the number of tasks running at once by limit,
+// such as an AIMDLimit or GradientLimit, rejecting the tasks past it if
+// reject is set, see Options.ConcurrencyLimit.
+func WithConcurrencyLimit(limit ConcurrencyLimit, reject bool) Option {
+	return func(opts *Options) {
+		opts.ConcurrencyLimit = limit
+		opts.RejectOverLimit = reject
+	}
+}
+
+// WithCoDel drops queued tasks while their waits stay above target for
+// interval, see Options.CoDelTarget.
+func WithCoDel(target, interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.CoDelTarget = target
+		opts.CoDelInterval = interval
+	}
+}
+
+// WithSLO fails submissions predicted by estimator to finish later than
+// slo, see Options.SLO. A nil estimator is a QueueEstimator.
+func WithSLO(slo time.Duration, estimator Estimator) Option {
+	return func(opts *Options) {
+		opts.SLO = slo
+		opts.SLOEstimator = estimator
+	}
+}
+
+// WithBacklogWatermarks calls onHigh once high tasks are queued, then onLow
+// once no more than low are, see Options.BacklogHigh.
+func WithBacklogWatermarks(high, low int, onHigh, onLow func(queued int)) Option {
+	return func(opts *Options) {
+		opts.BacklogHigh = high
+		opts.BacklogLow = low
+		opts.OnBacklogHigh = onHigh
+		opts.OnBacklogLow = onLow
+	}
+}
+
+// WithCPUGovernor throttles the tasks while the CPU usage of the process
+// stays above threshold, checked every interval, see Options.CPUThreshold.
+func WithCPUGovernor(threshold float64, interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.CPUThreshold = threshold
+		opts.CPUInterval = interval
+	}
+}
+
+// WithMemoryWatermark holds submissions while the heap usage is above
+// bytes, or rejects them if reject is set, see Options.MemoryWatermark.
+func WithMemoryWatermark(bytes uint64, reject bool) Option {
+	return func(opts *Options) {
+		opts.MemoryWatermark = bytes
+		opts.RejectOverMemory = reject
+	}
+}
+
+// WithMinWorkers keeps at least n workers alive however long the pool idles.
+func WithMinWorkers(n int) Option {
+	return func(opts *Options) {
+		opts.MinWorkers = n
+	}
+}
+
+// WithQueueCap bounds the number of queued tasks to n.
+func WithQueueCap(n int) Option {
+	return func(opts *Options) {
+		opts.QueueCap = n
+	}
+}
+
+// WithNonblocking indicates that Submit returns ErrQueueFull rather than
+// blocking when the queue is full.
+func WithNonblocking(nonblocking bool) Option {
+	return func(opts *Options) {
+		opts.Nonblocking = nonblocking
+	}
+}
+
+// WithMaxBlockingTasks sets up the maximum number of goroutines that are
+// blocked when the queue is full.
+func WithMaxBlockingTasks(maxBlockingTasks int) Option {
+	return func(opts *Options) {
+		opts.MaxBlockingTasks = maxBlockingTasks
+	}
+}
+
+// WithRateLimit lets tasks start at rate r with bursts of up to burst
+// tasks. Workers wait for their turn before running a task.
+func WithRateLimit(r rate.Limit, burst int) Option {
+	return func(opts *Options) {
+		opts.RateLimit = r
+		opts.RateBurst = burst
+	}
+}
+
+// WithRejectionPolicy sets up the policy applied to tasks submitted while
+// the queue is full.
+func WithRejectionPolicy(policy RejectionPolicy) Option {
+	return func(opts *Options) {
+		opts.RejectionPolicy = policy
+	}
+}
+
+// WithQueueFullHandler sets up the callback called with the tasks rejected
+// by the full queue, see Options.OnQueueFull.
+func WithQueueFullHandler(fn func(t RejectedTask)) Option {
+	return func(opts *Options) {
+		opts.OnQueueFull = fn
+	}
+}
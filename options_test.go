@@ -0,0 +1,130 @@
+package tinyPool
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestWithPreAlloc(t *testing.T) {
+	p, _ := NewPool(10, WithPreAlloc(8))
+	defer p.Close()
+
+	if n := p.Running(); n != 8 {
+		t.Fatalf("running = %d, want 8", n)
+	}
+}
+
+func TestWithExpiry(t *testing.T) {
+	p, _ := NewPool(10, WithPreAlloc(2), WithExpiry(10*time.Millisecond))
+	defer p.Close()
+
+	time.Sleep(200 * time.Millisecond)
+	if n := p.Running(); n != 0 {
+		t.Fatalf("running = %d, want 0 after expiry", n)
+	}
+}
+
+func TestWithQueue(t *testing.T) {
+	q := newMpscQueue()
+	p, _ := NewPool(1, WithQueue(q))
+	defer p.Close()
+
+	if p.shards[0].q.(*priorityQueue).levels[1] != q {
+		t.Fatal("custom queue not used")
+	}
+}
+
+func TestWithPanicHandler(t *testing.T) {
+	recovered := make(chan interface{}, 1)
+	p, _ := NewPool(1, WithPanicHandler(func(r interface{}, stack []byte) {
+		if len(stack) == 0 {
+			t.Error("empty stack trace")
+		}
+		recovered <- r
+	}))
+	defer p.Close()
+
+	_ = p.Submit(func() {
+		panic("boom")
+	})
+
+	select {
+	case r := <-recovered:
+		if r != "boom" {
+			t.Fatalf("recovered %v, want boom", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic handler was not called")
+	}
+}
+
+func TestWithMinWorkers(t *testing.T) {
+	p, _ := NewPool(10, WithMinWorkers(3), WithExpiry(10*time.Millisecond))
+	defer p.Close()
+
+	if n := p.Running(); n != 3 {
+		t.Fatalf("running = %d, want 3 warm workers", n)
+	}
+
+	release := saturate(p)
+	release()
+	time.Sleep(200 * time.Millisecond)
+	if n := p.Running(); n != 3 {
+		t.Fatalf("running = %d after expiry, want 3", n)
+	}
+}
+
+func TestWithDisablePurge(t *testing.T) {
+	p, _ := NewPool(10, WithPreAlloc(2), WithExpiryDuration(10*time.Millisecond), WithDisablePurge(true))
+	defer p.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	if n := p.Running(); n != 2 {
+		t.Fatalf("running = %d, want 2 with purge disabled", n)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	p, _ := NewPool(4, WithRateLimit(rate.Every(10*time.Millisecond), 1))
+	defer p.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(11)
+	start := time.Now()
+	for i := 0; i < 11; i++ {
+		_ = p.Submit(wg.Done)
+	}
+	wg.Wait()
+
+	if d := time.Since(start); d < 90*time.Millisecond {
+		t.Fatalf("11 tasks started within %v at 100 per second", d)
+	}
+}
+
+func TestWithCoreSize(t *testing.T) {
+	p, _ := NewPool(8, WithCoreSize(2, 4), WithExpiry(10*time.Millisecond))
+	defer p.Close()
+
+	block := make(chan struct{})
+	for i := 0; i < 7; i++ {
+		_ = p.Submit(func() { <-block })
+	}
+	if n := p.Running(); n != 2 {
+		t.Fatalf("running = %d with 5 tasks queued, want the core size 2", n)
+	}
+
+	// more than 4 tasks are queued, so the pool grows past its core
+	_ = p.Submit(func() { <-block })
+	if n := p.Running(); n != 3 {
+		t.Fatalf("running = %d with the queue past the threshold, want 3", n)
+	}
+
+	close(block)
+	time.Sleep(200 * time.Millisecond)
+	if n := p.Running(); n != 2 {
+		t.Fatalf("running = %d after expiry, want the core size 2", n)
+	}
+}
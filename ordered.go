@@ -0,0 +1,121 @@
+package tinyPool
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of a task emitted by an OrderedStream or sent by
+// SubmitTo.
+type Result[R any] struct {
+	Val R
+	Err error
+
+	// Duration is how long the task ran.
+	Duration time.Duration
+}
+
+// OrderedStream runs tasks on a pool in parallel and emits their results
+// in submission order. Each task gets a slot in a FIFO of at most window
+// slots, and a result is emitted once all slots before it are filled.
+type OrderedStream[R any] struct {
+	p     *Pool
+	slots chan chan Result[R]
+	out   chan Result[R]
+
+	mu     sync.Mutex
+	closed bool
+
+	// sending counts the Submits about to send their slot
+	sending sync.WaitGroup
+}
+
+// NewOrderedStream returns a stream running its tasks on p. At most window
+// results may be pending, further Submits block until the oldest result
+// is read.
+func NewOrderedStream[R any](p *Pool, window int) *OrderedStream[R] {
+	if window < 1 {
+		window = 1
+	}
+
+	s := &OrderedStream[R]{
+		p:     p,
+		slots: make(chan chan Result[R], window),
+		out:   make(chan Result[R]),
+	}
+	go s.emit()
+	return s
+}
+
+// Submit submits fn, its result is emitted after the results of all
+// earlier tasks. If the pool refuses or drops fn, the error is emitted as
+// its result. Submit returns ErrPoolClosed after Close.
+func (s *OrderedStream[R]) Submit(fn func() (R, error), opts ...TaskOption) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrPoolClosed
+	}
+	// Close waits for the slot, so the send below can't hit closed slots
+	s.sending.Add(1)
+	s.mu.Unlock()
+	defer s.sending.Done()
+
+	slot := make(chan Result[R], 1)
+	s.slots <- slot
+
+	var res Result[R]
+	j := newJob(opts)
+	j.fnErr = func() error {
+		start := time.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				slot <- Result[R]{Err: fmt.Errorf("task panicked: %v", r), Duration: time.Since(start)}
+				panic(r)
+			}
+		}()
+
+		val, err := fn()
+		res = Result[R]{Val: val, Err: err, Duration: time.Since(start)}
+		return err
+	}
+	j.finish = func() {
+		slot <- res
+	}
+	j.abort = func(err error) {
+		slot <- Result[R]{Err: err}
+	}
+
+	if err := s.p.submit(j); err != nil {
+		slot <- Result[R]{Err: err}
+	}
+	return nil
+}
+
+// Results returns the channel of results in submission order. It is
+// closed after Close, once all results have been emitted.
+func (s *OrderedStream[R]) Results() <-chan Result[R] {
+	return s.out
+}
+
+// Close ends the stream, tasks submitted before still emit their results.
+func (s *OrderedStream[R]) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.sending.Wait()
+	close(s.slots)
+}
+
+func (s *OrderedStream[R]) emit() {
+	defer close(s.out)
+	for slot := range s.slots {
+		s.out <- <-slot
+	}
+}
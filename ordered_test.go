@@ -0,0 +1,69 @@
+package tinyPool
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestOrderedStream(t *testing.T) {
+	p, _ := NewPool(8)
+	defer p.Close()
+
+	s := NewOrderedStream[int](p, 16)
+	go func() {
+		for i := 0; i < 100; i++ {
+			i := i
+			_ = s.Submit(func() (int, error) {
+				time.Sleep(time.Duration(rand.Intn(100)) * time.Microsecond)
+				return i, nil
+			})
+		}
+		s.Close()
+	}()
+
+	next := 0
+	for r := range s.Results() {
+		if r.Err != nil || r.Val != next {
+			t.Fatalf("got %v, want %d", r, next)
+		}
+		next++
+	}
+	if next != 100 {
+		t.Fatalf("got %d results, want 100", next)
+	}
+	if err := s.Submit(func() (int, error) { return 0, nil }); err != ErrPoolClosed {
+		t.Fatalf("submit after close: %v", err)
+	}
+}
+
+func TestOrderedStreamDropped(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := NewOrderedStream[int](p, 2)
+	_ = s.Submit(func() (int, error) { return 0, nil }, WithContext(ctx))
+	_ = s.Submit(func() (int, error) { return 1, nil })
+	s.Close()
+
+	var got []Result[int]
+	timeout := time.After(time.Second)
+	for done := false; !done; {
+		select {
+		case r, ok := <-s.Results():
+			if !ok {
+				done = true
+				break
+			}
+			got = append(got, r)
+		case <-timeout:
+			t.Fatal("stream stalled on a dropped task")
+		}
+	}
+	if len(got) != 2 || got[0].Err != context.Canceled || got[1].Val != 1 {
+		t.Fatalf("results = %v, want the drop error then 1", got)
+	}
+}
@@ -0,0 +1,88 @@
+// Package otel records the stats of a tinyPool pool with OpenTelemetry.
+package otel
+
+import (
+	"context"
+	"time"
+
+	"github.com/pandaknight2021/tinyPool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Source is a pool whose stats are recorded, both tinyPool.Pool and
+// tinyPool.PoolWithFunc implement it.
+type Source interface {
+	Stats() tinyPool.Stats
+	ObserveDurations(fn func(d time.Duration))
+}
+
+// InstrumentMetrics records the gauges and counters of p with meter on
+// every collection, and the duration of each task in a histogram. All of
+// them carry a "pool.name" attribute with name. The returned registration
+// stops the gauges and counters when unregistered, task durations are
+// recorded for the lifetime of p.
+func InstrumentMetrics(p Source, meter metric.Meter, name string) (metric.Registration, error) {
+	attrs := metric.WithAttributes(attribute.String("pool.name", name))
+
+	capacity, err := meter.Int64ObservableGauge("tinypool.capacity",
+		metric.WithDescription("Max number of workers."))
+	if err != nil {
+		return nil, err
+	}
+	running, err := meter.Int64ObservableGauge("tinypool.workers.running",
+		metric.WithDescription("Number of started workers."))
+	if err != nil {
+		return nil, err
+	}
+	idle, err := meter.Int64ObservableGauge("tinypool.workers.idle",
+		metric.WithDescription("Number of workers waiting for a task."))
+	if err != nil {
+		return nil, err
+	}
+	waiting, err := meter.Int64ObservableGauge("tinypool.queue.depth",
+		metric.WithDescription("Number of queued tasks."))
+	if err != nil {
+		return nil, err
+	}
+	submitted, err := meter.Int64ObservableCounter("tinypool.tasks.submitted",
+		metric.WithDescription("Number of accepted tasks."))
+	if err != nil {
+		return nil, err
+	}
+	completed, err := meter.Int64ObservableCounter("tinypool.tasks.completed",
+		metric.WithDescription("Number of finished tasks."))
+	if err != nil {
+		return nil, err
+	}
+	rejected, err := meter.Int64ObservableCounter("tinypool.tasks.rejected",
+		metric.WithDescription("Number of refused submissions."))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("tinypool.task.duration",
+		metric.WithDescription("How long tasks ran."), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	reg, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := p.Stats()
+		o.ObserveInt64(capacity, int64(s.Cap), attrs)
+		o.ObserveInt64(running, int64(s.Running), attrs)
+		o.ObserveInt64(idle, int64(s.Idle), attrs)
+		o.ObserveInt64(waiting, int64(s.Waiting), attrs)
+		o.ObserveInt64(submitted, s.Submitted, attrs)
+		o.ObserveInt64(completed, s.Completed, attrs)
+		o.ObserveInt64(rejected, s.Rejected, attrs)
+		return nil
+	}, capacity, running, idle, waiting, submitted, completed, rejected)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ObserveDurations(func(d time.Duration) {
+		duration.Record(context.Background(), d.Seconds(), attrs)
+	})
+	return reg, nil
+}
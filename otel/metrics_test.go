@@ -0,0 +1,53 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pandaknight2021/tinyPool"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestInstrumentMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+
+	p, _ := tinyPool.NewPool(4)
+	if _, err := InstrumentMetrics(p, meter, "test"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		_ = p.Submit(func() {})
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	found := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			found[m.Name] = true
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				if m.Name == "tinypool.tasks.completed" && data.DataPoints[0].Value != 3 {
+					t.Fatalf("completed = %d, want 3", data.DataPoints[0].Value)
+				}
+			case metricdata.Histogram[float64]:
+				if data.DataPoints[0].Count != 3 {
+					t.Fatalf("duration count = %d, want 3", data.DataPoints[0].Count)
+				}
+			}
+		}
+	}
+	for _, name := range []string{"tinypool.queue.depth", "tinypool.tasks.completed", "tinypool.task.duration"} {
+		if !found[name] {
+			t.Fatalf("metric %s not recorded", name)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pandaknight2021/tinyPool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace returns a task option which runs the task in a span started by
+// tracer as a child of the span in ctx, so the hop through the pool shows
+// up in the trace. Call it when submitting the task: the span starts at
+// that time, an event marks the task being picked up by a worker, and
+// the queue wait and run durations are recorded as attributes.
+func Trace(ctx context.Context, tracer trace.Tracer, name string) tinyPool.TaskOption {
+	submitted := time.Now()
+	return tinyPool.WithInterceptor(func(run func() error) (err error) {
+		_, span := tracer.Start(ctx, name, trace.WithTimestamp(submitted))
+		start := time.Now()
+		span.AddEvent("tinypool.start", trace.WithTimestamp(start))
+
+		defer func() {
+			span.SetAttributes(
+				attribute.Float64("tinypool.queue_wait", start.Sub(submitted).Seconds()),
+				attribute.Float64("tinypool.run", time.Since(start).Seconds()),
+			)
+			if r := recover(); r != nil {
+				span.SetStatus(codes.Error, fmt.Sprint("task panicked: ", r))
+				span.End()
+				panic(r)
+			}
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+
+		return run()
+	})
+}
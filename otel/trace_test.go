@@ -0,0 +1,42 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pandaknight2021/tinyPool"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	p, _ := tinyPool.NewPool(4)
+	ctx, parent := tracer.Start(context.Background(), "request")
+	_ = p.Submit(func() {}, Trace(ctx, tracer, "ok"))
+	_ = p.SubmitErr(func() error { return errors.New("bad") }, Trace(ctx, tracer, "failed"))
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("ended %d spans, want 3", len(spans))
+	}
+	for _, s := range spans[:2] {
+		if s.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Fatalf("span %s is not a child of the submitter span", s.Name())
+		}
+		if s.Name() == "failed" && s.Status().Code != codes.Error {
+			t.Fatalf("status of failed task = %v", s.Status())
+		}
+		if len(s.Attributes()) != 2 {
+			t.Fatalf("attributes = %v, want queue wait and run", s.Attributes())
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package tinyPool
+
+import "context"
+
+// Map runs fn on every element of in on the pool and returns the results
+// in the order of in. It stops at the first error: elements not started
+// by then are skipped, and the error is returned.
+func Map[T, R any](p *Pool, in []T, fn func(T) (R, error)) ([]R, error) {
+	out := make([]R, len(in))
+	g := p.Group(context.Background())
+	for i := range in {
+		if g.ctx.Err() != nil {
+			break
+		}
+
+		i := i
+		g.Go(func() error {
+			if g.ctx.Err() != nil {
+				return nil
+			}
+			r, err := fn(in[i])
+			out[i] = r
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ForEach runs fn on every element of in on the pool. It stops at the
+// first error like Map.
+func ForEach[T any](p *Pool, in []T, fn func(T) error) error {
+	_, err := Map(p, in, func(v T) (struct{}, error) {
+		return struct{}{}, fn(v)
+	})
+	return err
+}
+
+// Reduce maps every element of in with fn on the pool, then folds the
+// results into acc with merge, in the order of in.
+func Reduce[T, R, A any](p *Pool, in []T, fn func(T) (R, error), acc A, merge func(A, R) A) (A, error) {
+	out, err := Map(p, in, fn)
+	if err != nil {
+		return acc, err
+	}
+	for _, r := range out {
+		acc = merge(acc, r)
+	}
+	return acc, nil
+}
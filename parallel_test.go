@@ -0,0 +1,64 @@
+package tinyPool
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	in := make([]int, 100)
+	for i := range in {
+		in[i] = i
+	}
+	out, err := Map(p, in, func(n int) (string, error) {
+		return strconv.Itoa(n * 2), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range out {
+		if s != strconv.Itoa(i*2) {
+			t.Fatalf("out[%d] = %s, want %d", i, s, i*2)
+		}
+	}
+}
+
+func TestForEachError(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+	p.Tune(1)
+
+	boom := errors.New("boom")
+	var ran int32
+	err := ForEach(p, make([]int, 100), func(int) error {
+		if atomic.AddInt32(&ran, 1) == 3 {
+			return boom
+		}
+		return nil
+	})
+	if err != boom {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	if n := atomic.LoadInt32(&ran); n == 100 {
+		t.Fatal("ForEach did not stop at the first error")
+	}
+}
+
+func TestReduce(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	sum, err := Reduce(p, []int{1, 2, 3, 4}, func(n int) (int, error) {
+		return n * n, nil
+	}, 0, func(acc, n int) int {
+		return acc + n
+	})
+	if err != nil || sum != 30 {
+		t.Fatalf("got %d, %v, want 30", sum, err)
+	}
+}
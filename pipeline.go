@@ -0,0 +1,85 @@
+package tinyPool
+
+import (
+	"context"
+	"sync"
+)
+
+// Pipeline chains stages which each run on a pool of their own. Stages are
+// connected by bounded channels, so a slow stage holds back the stages
+// before it. The first error of a stage cancels the whole pipeline.
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wg sync.WaitGroup
+
+	errOnce sync.Once
+	err     error
+
+	mu    sync.Mutex
+	pools []*Pool
+}
+
+// NewPipeline returns an empty pipeline whose context is derived from ctx.
+func NewPipeline(ctx context.Context) *Pipeline {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Pipeline{ctx: ctx, cancel: cancel}
+}
+
+// Context returns the pipeline context, which is canceled when a stage
+// fails or Wait returns.
+func (pl *Pipeline) Context() context.Context {
+	return pl.ctx
+}
+
+// Stage adds a stage running fn on up to workers items of in at once, and
+// returns the channel of its outputs, buffering up to buffer of them. The
+// outputs are not ordered. The channel is closed when in is closed and
+// drained, or the pipeline is canceled.
+func Stage[In, Out any](pl *Pipeline, in <-chan In, workers, buffer int, fn func(context.Context, In) (Out, error)) <-chan Out {
+	if workers < 1 {
+		workers = 1
+	}
+	if buffer < 0 {
+		buffer = 0
+	}
+
+	p, _ := NewPool(workers)
+	p.Tune(workers)
+	pl.mu.Lock()
+	pl.pools = append(pl.pools, p)
+	pl.mu.Unlock()
+
+	out := make(chan Out, buffer)
+	pl.wg.Add(1)
+	go func() {
+		defer pl.wg.Done()
+		defer close(out)
+		process(pl.ctx, p, in, workers, out, fn, pl.fail)
+	}()
+
+	return out
+}
+
+// Wait blocks until all stages have finished, closes their pools and
+// returns the first error of a stage, if any. The output of the last stage
+// must be read until it is closed, or Wait blocks.
+func (pl *Pipeline) Wait() error {
+	pl.wg.Wait()
+	pl.cancel()
+
+	pl.mu.Lock()
+	for _, p := range pl.pools {
+		p.Close()
+	}
+	pl.mu.Unlock()
+	return pl.err
+}
+
+func (pl *Pipeline) fail(err error) {
+	pl.errOnce.Do(func() {
+		pl.err = err
+		pl.cancel()
+	})
+}
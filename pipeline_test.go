@@ -0,0 +1,69 @@
+package tinyPool
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	pl := NewPipeline(context.Background())
+
+	src := make(chan int)
+	go func() {
+		defer close(src)
+		for i := 1; i <= 100; i++ {
+			src <- i
+		}
+	}()
+
+	squares := Stage(pl, src, 4, 8, func(_ context.Context, n int) (int, error) {
+		return n * n, nil
+	})
+	strs := Stage(pl, squares, 2, 8, func(_ context.Context, n int) (string, error) {
+		return strconv.Itoa(n), nil
+	})
+
+	sum := 0
+	for s := range strs {
+		n, _ := strconv.Atoi(s)
+		sum += n
+	}
+	if err := pl.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 338350 {
+		t.Fatalf("sum = %d, want 338350", sum)
+	}
+}
+
+func TestPipelineError(t *testing.T) {
+	pl := NewPipeline(context.Background())
+
+	src := make(chan int)
+	go func() {
+		defer close(src)
+		for i := 0; ; i++ {
+			select {
+			case src <- i:
+			case <-pl.Context().Done():
+				return
+			}
+		}
+	}()
+
+	boom := errors.New("boom")
+	out := Stage(pl, src, 2, 0, func(_ context.Context, n int) (int, error) {
+		if n == 10 {
+			return 0, boom
+		}
+		return n, nil
+	})
+	for range out {
+	}
+
+	if err := pl.Wait(); err != boom {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+}
@@ -24,161 +24,217 @@
 package tinyPool
 
 import (
+	"context"
 	"errors"
-	"runtime"
 	"sync"
-	"sync/atomic"
 	"time"
-
-	"github.com/pandaknight2021/queue"
 )
 
 const (
 	// If workes idle for at least this period of time, then stop a worker.
-	expireTimeout = 2 * int(time.Second)
+	expireTimeout = 2 * time.Second
 )
 
-type Pool struct {
-	// capacity of the pool
-	capacity int32
+var (
+	// ErrPoolClosed will be returned when submitting task to a closed pool.
+	ErrPoolClosed = errors.New("pool closed")
 
-	//currently running goroutines
-	running int32
+	// ErrQueueFull will be returned when the bounded task queue is full
+	// and the pool is in nonblocking mode.
+	ErrQueueFull = errors.New("task queue is full")
 
-	idle int32
+	// ErrPoolOverload will be returned when too many submitters are
+	// already blocked waiting for a queue slot.
+	ErrPoolOverload = errors.New("too many goroutines blocked on submit")
 
-	q *queue.MpscQueue
+	// ErrTimeout will be returned when the pool cannot be closed in time.
+	ErrTimeout = errors.New("operation timed out")
 
-	//task queue -> task
-	task chan func()
+	// ErrTaskExpired is reported for a task whose deadline passed while
+	// it was queued.
+	ErrTaskExpired = errors.New("task expired in queue")
 
-	jobNum int32
+	// ErrTaskTimeout is reported for a task submitted by
+	// SubmitWithTimeout which ran out of time.
+	ErrTaskTimeout = errors.New("task timed out")
 
-	wg sync.WaitGroup
+	// ErrCircuitOpen will be returned when submitting a task whose tag
+	// failed too often recently, see WithCircuitBreaker.
+	ErrCircuitOpen = errors.New("circuit breaker is open")
 
-	quitSig chan struct{}
+	// ErrLimitExceeded will be returned when submitting a task past the
+	// concurrency limit, see WithConcurrencyLimit.
+	ErrLimitExceeded = errors.New("concurrency limit exceeded")
 
-	// expire time for recycle goroutine
-	expiry int
+	// ErrMemoryWatermark will be returned when submitting a task while the
+	// heap usage is above the watermark, see WithMemoryWatermark.
+	ErrMemoryWatermark = errors.New("heap usage above the memory watermark")
 
-	isClosed bool
-}
+	// ErrWouldMissSLO will be returned when submitting a task which is
+	// predicted to finish later than the latency SLO, see WithSLO.
+	ErrWouldMissSLO = errors.New("task would miss the latency SLO")
 
-// NewPool generates an instance of pool.
-func NewPool(size int) (*Pool, error) {
-	cap := runtime.NumCPU()
-	if cap < size {
-		cap = size
-	}
+	// ErrTaskDropped is reported for a task dropped to keep the queue
+	// wait down, see WithCoDel, or for a full queue, see
+	// DiscardOldestPolicy and DiscardNewestPolicy.
+	ErrTaskDropped = errors.New("task dropped from the queue")
 
-	p := &Pool{
-		capacity: int32(cap),
-		running:  int32(0),
-		task:     make(chan func()),
-		quitSig:  make(chan struct{}),
-		expiry:   expireTimeout,
-		isClosed: false,
-		jobNum:   0,
-		idle:     0,
-		q:        queue.NewMpscQueue(),
-	}
+	// ErrFlightType will be returned when SubmitShared joins a running
+	// task of the same key whose result has another type.
+	ErrFlightType = errors.New("shared task of the key has another result type")
+)
 
-	go p.dispatch()
+// Pool runs submitted tasks on a limited number of goroutines.
+type Pool struct {
+	*engine[job]
 
-	return p, nil
-}
+	events     chan TaskEvent
+	eventsOnce sync.Once
+	subscribed int32
 
-func (p *Pool) Submit(task func()) error {
-	if p.isClosed {
-		return errors.New("pool closed")
-	}
+	// wheel runs the timers of delayed and recurring tasks
+	wheel *timingWheel
 
-	if task != nil {
-		running := p.Running()
-		if running < p.capacity {
-			if atomic.CompareAndSwapInt32(&p.running, running, running+1) {
-				p.startOneWorker()
-			}
-		}
+	// timedOut counts tasks which ran out of time
+	timedOut int64
 
-		if idle := atomic.LoadInt32(&p.idle); idle > 0 {
-			p.task <- task
-		} else {
-			p.q.Push(task)
-		}
+	keys keyLanes
 
-		atomic.AddInt32(&p.jobNum, 1)
-	}
-	return nil
+	tags tagLimits
+
+	// breakers is nil unless WithCircuitBreaker is set
+	breakers *circuitBreakers
+
+	// results caches the results of SubmitShared, nil without
+	// WithResultCache
+	results *resultCache
+
+	// flights holds the Future of each running SubmitShared key
+	flights sync.Map
+
+	// hedges holds the latency window of hedged tasks by name
+	hedges sync.Map
+
+	// statuses tracks the tasks submitted by SubmitTracked
+	statuses taskStatuses
+
+	tenantsMu sync.Mutex
+	tenants   map[string]*Tenant
 }
 
-func (p *Pool) dispatch() {
-	ticker := time.NewTicker(time.Duration(p.expiry))
-	defer ticker.Stop()
-
-	go func() {
-		for !p.isClosed {
-			if p.q.Size() > 0 {
-				task := p.q.Pop()
-				p.task <- task.(func())
-			} else {
-				time.Sleep(10 * time.Microsecond)
-			}
+// NewPool generates an instance of pool. Its capacity is size, at least the
+// number of CPUs the process may use by GOMAXPROCS and its cgroup quota.
+func NewPool(size int, options ...Option) (*Pool, error) {
+	p := &Pool{}
+	// cap the slice, so append doesn't write into the array of the caller
+	options = append(options[:len(options):len(options)], withPriorityQueue())
+	p.engine = newEngine(size, p.runTask, options...)
+	p.submittedAt = func(j job) time.Time { return j.submitted }
+	p.deadlineOf = func(j job) time.Time { return j.deadline }
+	p.contextOf = func(j job) context.Context { return j.ctx }
+	p.nameOf = func(j job) string { return j.name }
+	p.tagOf = func(j job) string { return j.tag }
+	p.traceOf = func(j job) *taskTrace { return j.trace }
+	p.activeTasks = p.ActiveTasks
+	p.bind = func(j job, ws *WorkerState) job {
+		j.worker = ws
+		return j
+	}
+	p.abort = func(j job, err error) {
+		if j.abort != nil {
+			j.abort(err)
+		}
+	}
+	p.taskOf = func(j job) interface{} {
+		if j.fn != nil {
+			return j.fn
 		}
-	}()
-
-outer:
-	for {
-		n := p.jobNum
-		select {
-		case <-p.quitSig:
-			break outer
-
-		case <-ticker.C:
-			if n == p.jobNum {
-				if p.Running() > 0 {
-					p.stopOneWorker()
-				}
-			}
+		if j.task != nil {
+			return j.task
 		}
+		return nil
+	}
+	p.wheel = newTimingWheel(p.IsClosed)
+	// the wheel drops its timers once it sees the pool closed
+	p.onClose = p.wheel.wakeUp
+	p.tags.init(p.options.TagLimits)
+	if p.options.ResultCacheSize > 0 {
+		p.results = newResultCache(p.options.ResultCacheSize, p.options.ResultCacheTTL)
+	}
+	if p.options.BreakerThreshold > 0 {
+		p.breakers = newCircuitBreakers(p.options.BreakerThreshold, p.options.BreakerCooldown)
 	}
-}
 
-func (p *Pool) Close() {
-	p.isClosed = true
-	close(p.quitSig)
-	close(p.task)
-	p.wg.Wait()
+	return p, nil
 }
 
-func (p *Pool) Running() int32 {
-	return int32(atomic.LoadInt32(&p.running))
+// Submit submits a task to the pool.
+func (p *Pool) Submit(task func(), opts ...TaskOption) error {
+	if task == nil {
+		return nil
+	}
+
+	j := newJob(opts)
+	j.fn = task
+	return p.submit(j)
 }
 
-func (p *Pool) startOneWorker() {
-	go p.worker()
+// SubmitTask submits a Task to the pool.
+func (p *Pool) SubmitTask(task Task, opts ...TaskOption) error {
+	if task == nil {
+		return nil
+	}
+
+	j := newJob(opts)
+	j.task = task
+	return p.submit(j)
 }
 
-func (p *Pool) stopOneWorker() {
-	p.task <- nil
+// SubmitErr submits a task which may fail. Its error is passed to the
+// handler set by WithErrorHandler and reported in its TaskEvent.
+func (p *Pool) SubmitErr(task func() error, opts ...TaskOption) error {
+	if task == nil {
+		return nil
+	}
+
+	j := newJob(opts)
+	j.fnErr = task
+	return p.submit(j)
 }
 
-func (p *Pool) worker() {
-	p.wg.Add(1)
-	defer p.wg.Done()
+// TrySubmit submits a task without blocking. It returns false if the pool
+// is closed or has neither an idle worker nor a free queue slot.
+func (p *Pool) TrySubmit(task func(), opts ...TaskOption) bool {
+	if task == nil {
+		return false
+	}
 
-	atomic.AddInt32(&p.idle, 1)
-	defer atomic.AddInt32(&p.idle, -1)
+	j := newJob(opts)
+	j.fn = task
+	return p.trySubmit(j)
+}
 
-	defer atomic.AddInt32(&p.running, -1)
+// SubmitWithContext submits a task which receives ctx when it runs.
+// If ctx is done before a worker picks the task up, the task is abandoned,
+// and a submission blocked by BlockPolicy fails with ctx.Err(). The context
+// passed to the task is also canceled by ForceClose.
+func (p *Pool) SubmitWithContext(ctx context.Context, task func(ctx context.Context), opts ...TaskOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	for fn := range p.task {
-		if fn != nil {
-			atomic.AddInt32(&p.idle, -1)
-			fn()
-			atomic.AddInt32(&p.idle, 1)
-		} else {
-			break
-		}
+	if task == nil {
+		return p.Submit(nil)
 	}
+
+	// ctx also abandons the task while it waits for a queue slot
+	opts = append([]TaskOption{WithContext(ctx)}, opts...)
+	return p.Submit(func() {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(p.ctx, cancel)
+		defer stop()
+
+		task(ctx)
+	}, opts...)
 }
@@ -0,0 +1,47 @@
+package tinyPool
+
+import (
+	"errors"
+	"time"
+)
+
+// PoolWithFunc runs the same function for every argument passed to Invoke,
+// which saves allocating a closure per task.
+type PoolWithFunc[T any] struct {
+	*engine[invocation[T]]
+}
+
+// invocation is an argument passed to Invoke.
+type invocation[T any] struct {
+	arg       T
+	submitted time.Time
+	trace     *taskTrace
+}
+
+// NewPoolWithFunc generates an instance of pool that calls fn with each
+// invoked argument.
+func NewPoolWithFunc[T any](size int, fn func(T), options ...Option) (*PoolWithFunc[T], error) {
+	if fn == nil {
+		return nil, errors.New("must provide function for pool")
+	}
+
+	p := &PoolWithFunc[T]{
+		engine: newEngine(size, func(c invocation[T]) { fn(c.arg) }, options...),
+	}
+	p.submittedAt = func(c invocation[T]) time.Time { return c.submitted }
+	p.traceOf = func(c invocation[T]) *taskTrace { return c.trace }
+	p.taskOf = func(c invocation[T]) interface{} { return c.arg }
+
+	return p, nil
+}
+
+// Invoke submits arg to the pool.
+func (p *PoolWithFunc[T]) Invoke(arg T) error {
+	return p.submit(invocation[T]{arg: arg, submitted: time.Now(), trace: newTaskTrace("")})
+}
+
+// TryInvoke submits arg without blocking. It returns false if the pool is
+// closed or has neither an idle worker nor a free queue slot.
+func (p *PoolWithFunc[T]) TryInvoke(arg T) bool {
+	return p.trySubmit(invocation[T]{arg: arg, submitted: time.Now(), trace: newTaskTrace("")})
+}
@@ -0,0 +1,51 @@
+package tinyPool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPoolWithFunc(t *testing.T) {
+	var wg sync.WaitGroup
+	var sum int64
+	p, _ := NewPoolWithFunc(PoolSize, func(n int) {
+		atomic.AddInt64(&sum, int64(n))
+		wg.Done()
+	})
+	defer p.Close()
+
+	wg.Add(1000)
+	for i := 1; i <= 1000; i++ {
+		_ = p.Invoke(i)
+	}
+	wg.Wait()
+
+	if sum != 500500 {
+		t.Fatalf("sum = %d, want 500500", sum)
+	}
+}
+
+func TestPoolWithFuncNilFunc(t *testing.T) {
+	if _, err := NewPoolWithFunc[int](1, nil); err == nil {
+		t.Fatal("expected error for nil pool func")
+	}
+}
+
+func TestPoolWithFuncClosed(t *testing.T) {
+	p, _ := NewPoolWithFunc(1, func(int) {})
+	p.Close()
+
+	if err := p.Invoke(1); err == nil {
+		t.Fatal("Invoke on closed pool returned nil error")
+	}
+}
+
+func TestTryInvoke(t *testing.T) {
+	p, _ := NewPoolWithFunc(1, func(int) {})
+	p.Close()
+
+	if p.TryInvoke(1) {
+		t.Fatal("TryInvoke() = true on a closed pool")
+	}
+}
@@ -1,10 +1,14 @@
 package tinyPool
 
 import (
+	"context"
+	"errors"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unsafe"
 )
 
 const (
@@ -61,3 +65,362 @@ func TestTinyPool(t *testing.T) {
 	t.Logf("\tSTW = %vms\n", m.PauseTotalNs/1e6)
 	t.Logf("\tGCCPUFraction = %v\n", m.GCCPUFraction)
 }
+
+func TestSubmitWithContext(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	release := saturate(p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran int32
+	_ = p.SubmitWithContext(ctx, func(ctx context.Context) {
+		atomic.StoreInt32(&ran, 1)
+	})
+	cancel()
+	release()
+
+	if err := p.SubmitWithContext(ctx, func(ctx context.Context) {}); err != context.Canceled {
+		t.Fatalf("submit with canceled ctx: err = %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&ran) != 0 {
+		t.Fatal("task ran after its context was canceled")
+	}
+}
+
+// saturate occupies every worker of p until the returned func is called.
+func saturate(p *Pool) (release func()) {
+	var wg sync.WaitGroup
+	block := make(chan struct{})
+	wg.Add(int(p.Cap()))
+	for i := 0; i < int(p.Cap()); i++ {
+		_ = p.Submit(func() {
+			wg.Done()
+			<-block
+		})
+	}
+	wg.Wait()
+	return func() { close(block) }
+}
+
+func TestNewPoolOptions(t *testing.T) {
+	options := make([]Option, 1, 2)
+	options[0] = WithQueueCap(1)
+	marked := false
+	spare := options[:2]
+	spare[1] = func(*Options) { marked = true }
+
+	p, _ := NewPool(1, options...)
+	defer p.Close()
+	marked = false
+	spare[1](&Options{})
+	if !marked {
+		t.Fatal("NewPool wrote into the array of the options")
+	}
+}
+
+func TestQueueCapNonblocking(t *testing.T) {
+	p, _ := NewPool(1, WithQueueCap(2), WithNonblocking(true))
+	defer p.Close()
+
+	release := saturate(p)
+	defer release()
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = p.Submit(func() {})
+	}
+	if err != ErrQueueFull {
+		t.Fatalf("err = %v, want ErrQueueFull", err)
+	}
+}
+
+func TestQueueCapBlocking(t *testing.T) {
+	p, _ := NewPool(1, WithQueueCap(1))
+	defer p.Close()
+
+	release := saturate(p)
+
+	var submitted int32
+	go func() {
+		for i := 0; i < 10; i++ {
+			_ = p.Submit(func() {})
+			atomic.AddInt32(&submitted, 1)
+		}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&submitted); n >= 10 {
+		t.Fatalf("submitted %d tasks into a full queue without blocking", n)
+	}
+
+	release()
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&submitted); n != 10 {
+		t.Fatalf("submitted = %d after release, want 10", n)
+	}
+}
+
+func TestTrySubmit(t *testing.T) {
+	p, _ := NewPool(1, WithQueueCap(1))
+	defer p.Close()
+
+	done := make(chan struct{})
+	if !p.TrySubmit(func() { close(done) }) {
+		t.Fatal("TrySubmit() = false on an empty pool")
+	}
+	<-done
+
+	release := saturate(p)
+	defer release()
+
+	ok := true
+	for i := 0; i < 10 && ok; i++ {
+		ok = p.TrySubmit(func() {})
+	}
+	if ok {
+		t.Fatal("TrySubmit() kept succeeding on a saturated pool")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	p, _ := NewPool(2)
+
+	var done int32
+	for i := 0; i < 100; i++ {
+		_ = p.Submit(func() {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&done, 1)
+		})
+	}
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	if n := atomic.LoadInt32(&done); n != 100 {
+		t.Fatalf("%d of 100 tasks finished before Shutdown returned", n)
+	}
+	if err := p.Submit(func() {}); err != ErrPoolClosed {
+		t.Fatalf("Submit() after Shutdown = %v, want ErrPoolClosed", err)
+	}
+}
+
+func TestWait(t *testing.T) {
+	p, _ := NewPool(2)
+	defer p.Close()
+
+	var done int32
+	for i := 0; i < 100; i++ {
+		_ = p.Submit(func() {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&done, 1)
+		})
+	}
+
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+	if n := atomic.LoadInt32(&done); n != 100 {
+		t.Fatalf("%d of 100 tasks finished before Wait returned", n)
+	}
+	if err := p.Submit(func() {}); err != nil {
+		t.Fatalf("Submit() after Wait = %v", err)
+	}
+
+	release := saturate(p)
+	defer release()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Wait() = %v, want deadline exceeded", err)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	release := saturate(p)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Shutdown() = %v, want deadline exceeded", err)
+	}
+	if !p.IsClosed() {
+		t.Fatal("pool still accepts tasks after Shutdown")
+	}
+}
+
+func TestForceClose(t *testing.T) {
+	p, _ := NewPool(1)
+
+	var canceled int32
+	var wg sync.WaitGroup
+	wg.Add(int(p.Cap()))
+	for i := 0; i < int(p.Cap()); i++ {
+		_ = p.SubmitWithContext(context.Background(), func(ctx context.Context) {
+			wg.Done()
+			<-ctx.Done()
+			atomic.AddInt32(&canceled, 1)
+		})
+	}
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		_ = p.Submit(func() {
+			t.Error("dropped task ran")
+		})
+	}
+
+	if n := p.ForceClose(); n != 10 {
+		t.Fatalf("ForceClose() = %d, want 10", n)
+	}
+	if n := atomic.LoadInt32(&canceled); n != p.Cap() {
+		t.Fatalf("%d of %d running tasks were canceled", n, p.Cap())
+	}
+}
+
+func TestCloseTimeout(t *testing.T) {
+	p, _ := NewPool(1)
+
+	release := saturate(p)
+	defer release()
+	for i := 0; i < 10; i++ {
+		_ = p.Submit(func() {})
+	}
+
+	remaining, err := p.CloseTimeout(20 * time.Millisecond)
+	if err != ErrTimeout {
+		t.Fatalf("CloseTimeout() err = %v, want ErrTimeout", err)
+	}
+	if want := int(p.Cap()) + 10; remaining != want {
+		t.Fatalf("remaining = %d, want %d", remaining, want)
+	}
+
+	p, _ = NewPool(1)
+	_ = p.Submit(func() {})
+	if remaining, err := p.CloseTimeout(time.Second); remaining != 0 || err != nil {
+		t.Fatalf("CloseTimeout() = %d, %v, want 0, nil", remaining, err)
+	}
+}
+
+func TestReboot(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	p.Close()
+	if err := p.Submit(func() {}); err != ErrPoolClosed {
+		t.Fatalf("Submit() on closed pool = %v, want ErrPoolClosed", err)
+	}
+
+	p.Reboot()
+	done := make(chan struct{})
+	if err := p.Submit(func() { close(done) }); err != nil {
+		t.Fatalf("Submit() after Reboot = %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("task did not run after Reboot")
+	}
+}
+
+func TestTune(t *testing.T) {
+	p, _ := NewPool(PoolSize)
+	defer p.Close()
+
+	p.Tune(2)
+	if n := p.Cap(); n != 2 {
+		t.Fatalf("Cap() = %d, want 2", n)
+	}
+
+	release := saturate(p)
+	for i := 0; i < 10; i++ {
+		_ = p.Submit(func() {})
+	}
+	if n := p.Running(); n != 2 {
+		t.Fatalf("Running() = %d, want 2", n)
+	}
+
+	p.Tune(8)
+	release()
+	release = saturate(p)
+	if n := p.Running(); n != 8 {
+		t.Fatalf("Running() = %d after growing, want 8", n)
+	}
+
+	p.Tune(3)
+	release()
+	time.Sleep(50 * time.Millisecond)
+	if n := p.Running(); n > 3 {
+		t.Fatalf("Running() = %d after shrinking, want <= 3", n)
+	}
+}
+
+func TestPanicKeepsWorker(t *testing.T) {
+	var panics int32
+	p, _ := NewPool(1, WithPreAlloc(1), WithPanicHandler(func(interface{}, []byte) {
+		atomic.AddInt32(&panics, 1)
+	}))
+	defer p.Close()
+
+	running := p.Running()
+	for i := 0; i < 10; i++ {
+		_ = p.Submit(func() {
+			panic("boom")
+		})
+	}
+
+	done := make(chan struct{})
+	_ = p.Submit(func() { close(done) })
+	<-done
+
+	time.Sleep(10 * time.Millisecond)
+	if n := atomic.LoadInt32(&panics); n != 10 {
+		t.Fatalf("panic handler called %d times, want 10", n)
+	}
+	if n := p.Running(); n < running {
+		t.Fatalf("Running() = %d after panics, want at least %d", n, running)
+	}
+}
+
+func TestSubmitErr(t *testing.T) {
+	errs := make(chan error, 1)
+	p, _ := NewPool(1, WithErrorHandler(func(err error) {
+		errs <- err
+	}))
+	defer p.Close()
+
+	errBad := errors.New("bad")
+	_ = p.SubmitErr(func() error { return nil })
+	_ = p.SubmitErr(func() error { return errBad })
+
+	select {
+	case err := <-errs:
+		if err != errBad {
+			t.Fatalf("handled %v, want %v", err, errBad)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("error handler was not called")
+	}
+}
+
+func TestCounterPadding(t *testing.T) {
+	var p engine[job]
+	offsets := []uintptr{
+		unsafe.Offsetof(p.submitted),
+		unsafe.Offsetof(p.completed),
+		unsafe.Offsetof(p.pending),
+		unsafe.Offsetof(p.capacity),
+	}
+	for i := 1; i < len(offsets); i++ {
+		if offsets[i]-offsets[i-1] < 64 {
+			t.Fatalf("counter groups %d and %d are %d bytes apart, want a cache line", i-1, i, offsets[i]-offsets[i-1])
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package tinyPool
+
+// Priority is the scheduling priority of a task. Queued tasks of a higher
+// priority are handed to workers first.
+type Priority int
+
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+// WithPriority sets the priority of the task, PriorityNormal by default.
+func WithPriority(priority Priority) TaskOption {
+	return func(j *job) {
+		j.priority = priority
+	}
+}
+
+// SubmitPriority submits a task with the given priority.
+func (p *Pool) SubmitPriority(task func(), priority Priority, opts ...TaskOption) error {
+	return p.Submit(task, append(opts[:len(opts):len(opts)], WithPriority(priority))...)
+}
+
+// priorityQueue keeps a queue per priority and pops the most urgent task
+// first. Normal priority tasks go to the configured queue.
+type priorityQueue struct {
+	levels [3]Queue
+
+	// batch is the level of the last batch popped, only the feeder uses it
+	batch int
+}
+
+// withPriorityQueue wraps the configured queue into a priorityQueue.
+func withPriorityQueue() Option {
+	return func(opts *Options) {
+		normal := opts.Queue
+		if normal == nil {
+			normal = newQueue(opts)
+			opts.shardQueue = func() Queue { return newPriorityQueue(opts, newQueue(opts)) }
+		}
+		opts.Queue = newPriorityQueue(opts, normal)
+	}
+}
+
+// newPriorityQueue returns a priorityQueue keeping normal priority tasks
+// in normal.
+func newPriorityQueue(opts *Options, normal Queue) *priorityQueue {
+	return &priorityQueue{
+		levels: [3]Queue{newQueue(opts), normal, newQueue(opts)},
+	}
+}
+
+func (q *priorityQueue) level(priority Priority) Queue {
+	switch {
+	case priority < PriorityNormal:
+		return q.levels[0]
+	case priority > PriorityNormal:
+		return q.levels[2]
+	}
+	return q.levels[1]
+}
+
+func (q *priorityQueue) Push(v interface{}) bool {
+	return q.level(v.(*job).priority).Push(v)
+}
+
+func (q *priorityQueue) Pop() interface{} {
+	for i := len(q.levels) - 1; i >= 0; i-- {
+		if q.levels[i].Size() > 0 {
+			return q.levels[i].Pop()
+		}
+	}
+	return nil
+}
+
+// popBatch pops a batch from the most urgent level only, so tasks of
+// different priorities never share a batch.
+func (q *priorityQueue) popBatch(buf []interface{}) int {
+	for i := len(q.levels) - 1; i >= 0; i-- {
+		if q.levels[i].Size() > 0 {
+			q.batch = i
+			return popBatch(q.levels[i], buf)
+		}
+	}
+	return 0
+}
+
+// popUrgent pops a task of a higher priority than the last batch.
+func (q *priorityQueue) popUrgent() interface{} {
+	for i := len(q.levels) - 1; i > q.batch; i-- {
+		if q.levels[i].Size() > 0 {
+			return q.levels[i].Pop()
+		}
+	}
+	return nil
+}
+
+func (q *priorityQueue) Empty() bool {
+	return q.Size() == 0
+}
+
+func (q *priorityQueue) Size() int64 {
+	var n int64
+	for _, l := range q.levels {
+		n += l.Size()
+	}
+	return n
+}
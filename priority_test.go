@@ -0,0 +1,94 @@
+package tinyPool
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSubmitPriority(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+	p.Tune(1)
+
+	release := saturate(p)
+
+	var mu sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	for _, prio := range []Priority{PriorityLow, PriorityLow, PriorityNormal, PriorityHigh} {
+		prio := prio
+		wg.Add(1)
+		_ = p.SubmitPriority(func() {
+			mu.Lock()
+			order = append(order, prio)
+			mu.Unlock()
+			wg.Done()
+		}, prio)
+	}
+
+	release()
+	wg.Wait()
+
+	// the dispatcher may already hold the first low priority task
+	if order[0] == PriorityLow {
+		order = order[1:]
+	}
+	if order[0] != PriorityHigh || order[1] != PriorityNormal {
+		t.Fatalf("tasks ran in order %v", order)
+	}
+}
+
+func TestPriorityQueuePopBatch(t *testing.T) {
+	q := newPriorityQueue(&Options{}, newMpscQueue())
+	for i := 0; i < 3; i++ {
+		q.Push(&job{name: "normal"})
+	}
+	q.Push(&job{name: "high", priority: PriorityHigh})
+	q.Push(&job{name: "high", priority: PriorityHigh})
+
+	buf := make([]interface{}, dispatchBatch)
+	if n := q.popBatch(buf); n != 2 || buf[0].(*job).name != "high" {
+		t.Fatalf("first batch has %d tasks, want the 2 high ones", n)
+	}
+	if n := q.popBatch(buf); n != 3 || buf[0].(*job).name != "normal" {
+		t.Fatalf("second batch has %d tasks, want the 3 normal ones", n)
+	}
+	if n := q.popBatch(buf); n != 0 {
+		t.Fatalf("popped %d tasks from the empty queue", n)
+	}
+}
+
+func TestPriorityQueuePopUrgent(t *testing.T) {
+	q := newPriorityQueue(&Options{}, newMpscQueue())
+	for i := 0; i < 3; i++ {
+		q.Push(&job{name: "normal"})
+	}
+	buf := make([]interface{}, dispatchBatch)
+	q.popBatch(buf)
+
+	q.Push(&job{name: "low", priority: PriorityLow})
+	if v := q.popUrgent(); v != nil {
+		t.Fatalf("popped %s ahead of a normal batch", v.(*job).name)
+	}
+	q.Push(&job{name: "high", priority: PriorityHigh})
+	if v := q.popUrgent(); v == nil || v.(*job).name != "high" {
+		t.Fatalf("popUrgent = %v, want the high task", v)
+	}
+}
+
+func TestSubmitPriorityOptions(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	opts := make([]TaskOption, 1, 2)
+	opts[0] = WithTaskName("a")
+	spare := opts[:2]
+	spare[1] = WithTaskName("b")
+
+	_ = p.SubmitPriority(func() {}, PriorityHigh, opts...)
+	var j job
+	spare[1](&j)
+	if j.name != "b" {
+		t.Fatal("SubmitPriority wrote into the array of the options")
+	}
+}
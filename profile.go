@@ -0,0 +1,23 @@
+package tinyPool
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// profiled runs exec with item under the pprof labels "pool" and "tag",
+// so profiles tell tasks apart by the pool and tag they belong to.
+func (p *engine[T]) profiled(item T, exec func(T)) {
+	if !p.options.PprofLabels {
+		exec(item)
+		return
+	}
+
+	labels := []string{"pool", p.options.Name}
+	if tag := p.tag(item); tag != "" {
+		labels = append(labels, "tag", tag)
+	}
+	pprof.Do(context.Background(), pprof.Labels(labels...), func(context.Context) {
+		exec(item)
+	})
+}
@@ -0,0 +1,25 @@
+package tinyPool
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithPprofLabels(t *testing.T) {
+	p, _ := NewPool(1, WithName("export"), WithPprofLabels())
+	defer p.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+	_ = p.Submit(func() { <-release }, WithTag("db"))
+	time.Sleep(20 * time.Millisecond)
+
+	var buf bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&buf, 1)
+	if !strings.Contains(buf.String(), `labels: {"pool":"export", "tag":"db"}`) {
+		t.Fatalf("no labeled goroutine in\n%s", buf.String())
+	}
+}
@@ -0,0 +1,56 @@
+package tinyPool
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync/atomic"
+)
+
+// Progress is passed to the tasks submitted by SubmitWithProgress to
+// report how far they have gotten.
+type Progress struct {
+	// bits holds the float64 bits of the fraction done
+	bits uint64
+}
+
+// Set reports the fraction of the work done, between 0 and 1.
+func (pr *Progress) Set(fraction float64) {
+	fraction = math.Max(0, math.Min(1, fraction))
+	atomic.StoreUint64(&pr.bits, math.Float64bits(fraction))
+}
+
+// Value returns the latest fraction set.
+func (pr *Progress) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&pr.bits))
+}
+
+// SubmitWithProgress is like SubmitCancelable for tasks which report
+// their progress, which is shown in their status and by DebugHandler.
+func (p *Pool) SubmitWithProgress(task func(ctx context.Context, pr *Progress) error, opts ...TaskOption) (*TaskHandle, error) {
+	ctx, cancel := context.WithCancel(p.closing)
+	h := &TaskHandle{done: make(chan struct{}), ctx: ctx, cancel: cancel, progress: &Progress{}, p: p}
+	return p.submitTracked(h, func() error {
+		return task(ctx, h.progress)
+	}, append(opts[:len(opts):len(opts)], WithContext(ctx)))
+}
+
+// ActiveTasks returns the status of the tracked tasks which have not
+// finished yet, by ID.
+func (p *Pool) ActiveTasks() []TaskStatus {
+	p.statuses.mu.Lock()
+	handles := make([]*TaskHandle, 0, len(p.statuses.handles))
+	for _, h := range p.statuses.handles {
+		handles = append(handles, h)
+	}
+	p.statuses.mu.Unlock()
+
+	var active []TaskStatus
+	for _, h := range handles {
+		if s := h.Status(); !s.State.finished() {
+			active = append(active, s)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].ID < active[j].ID })
+	return active
+}
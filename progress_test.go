@@ -0,0 +1,53 @@
+package tinyPool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSubmitWithProgress(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	halfway := make(chan struct{})
+	release := make(chan struct{})
+	h, _ := p.SubmitWithProgress(func(ctx context.Context, pr *Progress) error {
+		pr.Set(0.42)
+		close(halfway)
+		<-release
+		pr.Set(2)
+		return nil
+	}, WithTaskName("import"))
+	<-halfway
+
+	if s := h.Status(); s.Progress != 0.42 {
+		t.Fatalf("progress = %v, want 0.42", s.Progress)
+	}
+	active := p.ActiveTasks()
+	if len(active) != 1 || active[0].ID != h.ID() || active[0].Progress != 0.42 {
+		t.Fatalf("active tasks = %+v", active)
+	}
+
+	rec := httptest.NewRecorder()
+	p.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/?format=json", nil))
+	var state debugState
+	if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Tasks) != 1 || state.Tasks[0].Name != "import" || state.Tasks[0].Progress != 0.42 {
+		t.Fatalf("debug tasks = %+v", state.Tasks)
+	}
+
+	close(release)
+	if err := h.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := h.Status(); s.Progress != 1 {
+		t.Fatalf("progress = %v, want clamped to 1", s.Progress)
+	}
+	if active := p.ActiveTasks(); len(active) != 0 {
+		t.Fatalf("active tasks = %+v after finishing", active)
+	}
+}
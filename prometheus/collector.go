@@ -0,0 +1,85 @@
+// Package prometheus exports the stats of a tinyPool pool as Prometheus
+// metrics.
+package prometheus
+
+import (
+	"github.com/pandaknight2021/tinyPool"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// Source is a pool whose stats are collected, both tinyPool.Pool and
+// tinyPool.PoolWithFunc implement it.
+type Source interface {
+	Stats() tinyPool.Stats
+	Durations() tinyPool.DurationHistogram
+}
+
+// Collector implements prometheus.Collector for a pool. The metrics carry
+// a "pool" label with the name given to NewCollector.
+type Collector struct {
+	p    Source
+	name string
+
+	capacity  *prom.Desc
+	running   *prom.Desc
+	idle      *prom.Desc
+	waiting   *prom.Desc
+	submitted *prom.Desc
+	completed *prom.Desc
+	rejected  *prom.Desc
+	duration  *prom.Desc
+}
+
+// NewCollector returns a collector of the stats of p, which should be
+// registered with a prometheus.Registerer.
+func NewCollector(name string, p Source) *Collector {
+	desc := func(metric, help string) *prom.Desc {
+		return prom.NewDesc("tinypool_"+metric, help, nil, prom.Labels{"pool": name})
+	}
+
+	return &Collector{
+		p:         p,
+		name:      name,
+		capacity:  desc("capacity", "Max number of workers."),
+		running:   desc("workers_running", "Number of started workers."),
+		idle:      desc("workers_idle", "Number of workers waiting for a task."),
+		waiting:   desc("queue_depth", "Number of queued tasks."),
+		submitted: desc("tasks_submitted_total", "Number of accepted tasks."),
+		completed: desc("tasks_completed_total", "Number of finished tasks."),
+		rejected:  desc("tasks_rejected_total", "Number of refused submissions."),
+		duration:  desc("task_duration_seconds", "How long tasks ran."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	ch <- c.capacity
+	ch <- c.running
+	ch <- c.idle
+	ch <- c.waiting
+	ch <- c.submitted
+	ch <- c.completed
+	ch <- c.rejected
+	ch <- c.duration
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	s := c.p.Stats()
+	ch <- prom.MustNewConstMetric(c.capacity, prom.GaugeValue, float64(s.Cap))
+	ch <- prom.MustNewConstMetric(c.running, prom.GaugeValue, float64(s.Running))
+	ch <- prom.MustNewConstMetric(c.idle, prom.GaugeValue, float64(s.Idle))
+	ch <- prom.MustNewConstMetric(c.waiting, prom.GaugeValue, float64(s.Waiting))
+	ch <- prom.MustNewConstMetric(c.submitted, prom.CounterValue, float64(s.Submitted))
+	ch <- prom.MustNewConstMetric(c.completed, prom.CounterValue, float64(s.Completed))
+	ch <- prom.MustNewConstMetric(c.rejected, prom.CounterValue, float64(s.Rejected))
+
+	h := c.p.Durations()
+	buckets := make(map[float64]uint64, len(h.Bounds))
+	var cumulative uint64
+	for i, bound := range h.Bounds {
+		cumulative += h.Counts[i]
+		buckets[bound.Seconds()] = cumulative
+	}
+	ch <- prom.MustNewConstHistogram(c.duration, h.Count, h.Sum.Seconds(), buckets)
+}
@@ -0,0 +1,43 @@
+package prometheus
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pandaknight2021/tinyPool"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	p, _ := tinyPool.NewPool(4)
+	for i := 0; i < 3; i++ {
+		_ = p.Submit(func() {})
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := prom.NewPedanticRegistry()
+	reg.MustRegister(NewCollector("test", p))
+
+	expected := `
+# HELP tinypool_tasks_completed_total Number of finished tasks.
+# TYPE tinypool_tasks_completed_total counter
+tinypool_tasks_completed_total{pool="test"} 3
+# HELP tinypool_queue_depth Number of queued tasks.
+# TYPE tinypool_queue_depth gauge
+tinypool_queue_depth{pool="test"} 0
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"tinypool_tasks_completed_total", "tinypool_queue_depth")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := testutil.GatherAndCount(reg, "tinypool_task_duration_seconds")
+	if err != nil || n != 1 {
+		t.Fatalf("duration histogram count = %d, %v", n, err)
+	}
+}
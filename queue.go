@@ -0,0 +1,125 @@
+package tinyPool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Queue buffers tasks while all workers are busy. Push may be called from
+// multiple goroutines, Pop, Empty and Size from the dispatcher only, which
+// also reads Size from other goroutines for stats. The pool pushes pointers
+// to its tasks, which it reuses once they are popped.
+type Queue interface {
+	// Push puts v at the tail of the queue.
+	Push(v interface{}) bool
+
+	// Pop removes and returns the value at the head of the queue, nil if
+	// the queue is empty.
+	Pop() interface{}
+
+	Empty() bool
+	Size() int64
+}
+
+// mpscNode is a linked node of an mpscQueue.
+type mpscNode struct {
+	next atomic.Pointer[mpscNode]
+	val  interface{}
+}
+
+// mpscNodes recycles the nodes popped from all queues, so a steady flow of
+// tasks doesn't allocate.
+var mpscNodes = sync.Pool{
+	New: func() interface{} { return new(mpscNode) },
+}
+
+// mpscQueue is the lock-free multi-producer single-consumer queue of
+// Vyukov: producers swap themselves in at the head, the consumer follows
+// the links from a stub node at the tail.
+type mpscQueue struct {
+	// head is the latest pushed node, written by producers
+	head atomic.Pointer[mpscNode]
+	_    cacheLinePad
+
+	// tail is the stub node before the next one to pop, owned by the
+	// consumer
+	tail *mpscNode
+	_    cacheLinePad
+
+	size int64
+}
+
+func newMpscQueue() *mpscQueue {
+	stub := new(mpscNode)
+	q := &mpscQueue{tail: stub}
+	q.head.Store(stub)
+	return q
+}
+
+// Push implements Queue.
+func (q *mpscQueue) Push(v interface{}) bool {
+	n := mpscNodes.Get().(*mpscNode)
+	n.val = v
+	prev := q.head.Swap(n)
+	// until this store the consumer can't see n, nor nodes pushed after it
+	prev.next.Store(n)
+	atomic.AddInt64(&q.size, 1)
+	return true
+}
+
+// Pop implements Queue. It may return nil while a concurrent Push is still
+// linking its node, even though Size counts other pushed nodes already.
+func (q *mpscQueue) Pop() interface{} {
+	v, ok := q.pop()
+	if !ok {
+		return nil
+	}
+	atomic.AddInt64(&q.size, -1)
+	return v
+}
+
+// popBatch pops up to len(buf) values into buf and returns their number.
+// The size of the queue is updated once for all of them.
+func (q *mpscQueue) popBatch(buf []interface{}) int {
+	n := 0
+	for n < len(buf) {
+		v, ok := q.pop()
+		if !ok {
+			break
+		}
+		buf[n] = v
+		n++
+	}
+	if n > 0 {
+		atomic.AddInt64(&q.size, -int64(n))
+	}
+	return n
+}
+
+// pop unlinks the value at the head of the queue without counting it.
+func (q *mpscQueue) pop() (interface{}, bool) {
+	tail := q.tail
+	next := tail.next.Load()
+	if next == nil {
+		return nil, false
+	}
+
+	// next becomes the stub, so the old one is free: producers only
+	// touch the node they swapped out of head, and it has been linked
+	q.tail = next
+	v := next.val
+	next.val = nil
+	tail.next.Store(nil)
+	mpscNodes.Put(tail)
+	return v, true
+}
+
+// Empty implements Queue.
+func (q *mpscQueue) Empty() bool {
+	return q.tail.next.Load() == nil
+}
+
+// Size implements Queue.
+func (q *mpscQueue) Size() int64 {
+	return atomic.LoadInt64(&q.size)
+}
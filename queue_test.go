@@ -0,0 +1,48 @@
+package tinyPool
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMpscQueue(t *testing.T) {
+	q := newMpscQueue()
+	if v := q.Pop(); v != nil || !q.Empty() {
+		t.Fatalf("Pop() = %v from an empty queue", v)
+	}
+
+	const producers, n = 8, 10000
+	var wg sync.WaitGroup
+	wg.Add(producers)
+	for p := 0; p < producers; p++ {
+		p := p
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				q.Push(p*n + i)
+			}
+		}()
+	}
+
+	// every producer's values come out in order
+	last := make([]int, producers)
+	for i := range last {
+		last[i] = -1
+	}
+	buf := make([]interface{}, 64)
+	for got := 0; got < producers*n; {
+		k := q.popBatch(buf)
+		for _, v := range buf[:k] {
+			p, i := v.(int)/n, v.(int)%n
+			if i <= last[p] {
+				t.Fatalf("producer %d: %d popped after %d", p, i, last[p])
+			}
+			last[p] = i
+		}
+		got += k
+	}
+	wg.Wait()
+	if s := q.Size(); s != 0 || !q.Empty() {
+		t.Fatalf("size = %d after popping all", s)
+	}
+}
@@ -0,0 +1,227 @@
+package tinyPool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// RejectionPolicy decides what happens to a task submitted while the
+// bounded queue is full.
+type RejectionPolicy interface {
+	// Reject handles the rejected task r. The returned error is passed
+	// back to the submitter, unless r was discarded.
+	Reject(r Rejection) error
+}
+
+// Rejection is a task which could not be queued.
+type Rejection interface {
+	// Run executes the task in the calling goroutine.
+	Run()
+
+	// Wait blocks until there is room for the task in the queue, then
+	// queues it. It fails with ErrPoolClosed if the pool is closed first,
+	// with the error of the context of the task if it is done first, see
+	// WithContext, or with ErrPoolOverload if too many submitters are
+	// already blocked.
+	Wait() error
+
+	// DiscardOldest drops the oldest queued task and queues this one.
+	DiscardOldest()
+
+	// Discard drops this task instead of queueing it.
+	Discard()
+}
+
+// DroppedTask is a task dropped by DiscardOldestPolicy or
+// DiscardNewestPolicy. Its Future or tracked status, if any, fails with
+// ErrTaskDropped.
+type DroppedTask struct {
+	Name string
+
+	// Wait is how long the task was queued, 0 for a task dropped as it
+	// was submitted.
+	Wait time.Duration
+
+	// Task is the argument of a task invoked on a PoolWithFunc, or the
+	// func() or Task given to Submit or SubmitTask of a Pool, nil for the
+	// tasks submitted otherwise.
+	Task interface{}
+}
+
+// RejectedTask is a task rejected by the full queue, see
+// Options.OnQueueFull.
+type RejectedTask struct {
+	Name string
+
+	// Task is the task as in DroppedTask, to divert it elsewhere.
+	Task interface{}
+
+	// Err is the error returned to the submitter.
+	Err error
+
+	// Stats is the state of the pool right after the rejection.
+	Stats Stats
+}
+
+// queueFull passes item, rejected by the full queue with err, to the
+// OnQueueFull callback.
+func (p *engine[T]) queueFull(item T, err error) {
+	if fn := p.options.OnQueueFull; fn != nil {
+		fn(RejectedTask{Name: p.name(item), Task: p.payload(item), Err: err, Stats: p.Stats()})
+	}
+}
+
+// dropObserver is a RejectionPolicy told about the tasks it dropped.
+type dropObserver interface {
+	dropped(t DroppedTask)
+}
+
+// AbortPolicy fails the submission with ErrQueueFull.
+type AbortPolicy struct{}
+
+// Reject implements RejectionPolicy.
+func (AbortPolicy) Reject(r Rejection) error {
+	return ErrQueueFull
+}
+
+// BlockPolicy blocks the submitter until the queue has room.
+type BlockPolicy struct{}
+
+// Reject implements RejectionPolicy.
+func (BlockPolicy) Reject(r Rejection) error {
+	return r.Wait()
+}
+
+// CallerRunsPolicy runs the task in the submitting goroutine, which also
+// slows producers down while the pool is saturated.
+type CallerRunsPolicy struct{}
+
+// Reject implements RejectionPolicy.
+func (CallerRunsPolicy) Reject(r Rejection) error {
+	r.Run()
+	return nil
+}
+
+// DiscardOldestPolicy drops the oldest queued task to make room for the
+// submitted one, the head drop which suits workloads where fresh tasks
+// matter most. OnDrop, if set, is called with each dropped task, once the
+// dispatcher takes it out of the queue.
+type DiscardOldestPolicy struct {
+	OnDrop func(t DroppedTask)
+}
+
+// Reject implements RejectionPolicy.
+func (DiscardOldestPolicy) Reject(r Rejection) error {
+	r.DiscardOldest()
+	return nil
+}
+
+func (d DiscardOldestPolicy) dropped(t DroppedTask) {
+	if d.OnDrop != nil {
+		d.OnDrop(t)
+	}
+}
+
+// DiscardNewestPolicy drops the submitted task, the tail drop which keeps
+// the queued tasks in order. The submission doesn't fail, unlike with
+// AbortPolicy. OnDrop, if set, is called with each dropped task.
+type DiscardNewestPolicy struct {
+	OnDrop func(t DroppedTask)
+}
+
+// Reject implements RejectionPolicy.
+func (DiscardNewestPolicy) Reject(r Rejection) error {
+	r.Discard()
+	return nil
+}
+
+func (d DiscardNewestPolicy) dropped(t DroppedTask) {
+	if d.OnDrop != nil {
+		d.OnDrop(t)
+	}
+}
+
+type rejection[T any] struct {
+	p    *engine[T]
+	item T
+
+	// discarded is set once Discard dropped item
+	discarded bool
+}
+
+func (r *rejection[T]) Run() {
+	r.p.run(r.item)
+}
+
+func (r *rejection[T]) Wait() error {
+	if max := int32(r.p.options.MaxBlockingTasks); max > 0 {
+		if atomic.AddInt32(&r.p.blocking, 1) > max {
+			atomic.AddInt32(&r.p.blocking, -1)
+			return ErrPoolOverload
+		}
+		defer atomic.AddInt32(&r.p.blocking, -1)
+	}
+
+	var ctx context.Context
+	var done <-chan struct{}
+	if r.p.contextOf != nil {
+		if ctx = r.p.contextOf(r.item); ctx != nil {
+			done = ctx.Done()
+		}
+	}
+
+	select {
+	case r.p.slots <- struct{}{}:
+	case <-r.p.quitSig:
+		return ErrPoolClosed
+	case <-done:
+		return ctx.Err()
+	}
+
+	r.p.push(r.item)
+	return nil
+}
+
+func (r *rejection[T]) DiscardOldest() {
+	// the dispatcher drops the oldest task and hands its slot over to item
+	atomic.AddInt32(&r.p.discard, 1)
+	r.p.push(r.item)
+}
+
+func (r *rejection[T]) Discard() {
+	r.discarded = true
+	r.p.discarded(r.item, 0)
+}
+
+// discarded drops item, which was queued for wait, for the rejection policy.
+func (p *engine[T]) discarded(item T, wait time.Duration) {
+	p.drop(item, wait)
+	if o, ok := p.options.RejectionPolicy.(dropObserver); ok {
+		o.dropped(DroppedTask{Name: p.name(item), Wait: wait, Task: p.payload(item)})
+	}
+}
+
+// discardOldest drops item, just taken out of the queue, to make room for a
+// task queued by DiscardOldest.
+func (p *engine[T]) discardOldest(item T) {
+	var wait time.Duration
+	if p.submittedAt != nil {
+		wait = time.Since(p.submittedAt(item))
+	}
+	p.discarded(item, wait)
+}
+
+// dropOldest reports whether the task just popped by the dispatcher has to
+// be dropped to make room for a task queued by DiscardOldest.
+func (p *engine[T]) dropOldest() bool {
+	for {
+		n := atomic.LoadInt32(&p.discard)
+		if n == 0 {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&p.discard, n, n-1) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package tinyPool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAbortPolicy(t *testing.T) {
+	p, _ := NewPool(1, WithQueueCap(1), WithRejectionPolicy(AbortPolicy{}))
+	defer p.Close()
+
+	release := saturate(p)
+	defer release()
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = p.Submit(func() {})
+	}
+	if err != ErrQueueFull {
+		t.Fatalf("err = %v, want ErrQueueFull", err)
+	}
+}
+
+func TestCallerRunsPolicy(t *testing.T) {
+	p, _ := NewPool(1, WithQueueCap(1), WithRejectionPolicy(CallerRunsPolicy{}))
+	defer p.Close()
+
+	release := saturate(p)
+	defer release()
+
+	var callerRuns int32
+	for i := 0; i < 10; i++ {
+		ran := false
+		if err := p.Submit(func() { ran = true }); err != nil {
+			t.Fatalf("Submit() = %v", err)
+		}
+		if ran {
+			callerRuns++
+		}
+	}
+	if callerRuns == 0 {
+		t.Fatal("no task ran in the caller")
+	}
+}
+
+func TestDiscardOldestPolicy(t *testing.T) {
+	p, _ := NewPool(1, WithQueueCap(1), WithRejectionPolicy(DiscardOldestPolicy{}))
+	defer p.Close()
+
+	release := saturate(p)
+
+	var ran int32
+	for i := 0; i < 10; i++ {
+		if err := p.Submit(func() { atomic.AddInt32(&ran, 1) }); err != nil {
+			t.Fatalf("Submit() = %v", err)
+		}
+	}
+
+	release()
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&ran); n == 0 || n >= 10 {
+		t.Fatalf("ran %d of 10 tasks, want some of them discarded", n)
+	}
+}
+
+func TestMaxBlockingTasks(t *testing.T) {
+	p, _ := NewPool(1, WithQueueCap(1), WithMaxBlockingTasks(2))
+	defer p.Close()
+
+	release := saturate(p)
+
+	var wg sync.WaitGroup
+	wg.Add(10)
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			errs <- p.Submit(wg.Done)
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	release()
+
+	overload := 0
+	for i := 0; i < 10; i++ {
+		if err := <-errs; err == ErrPoolOverload {
+			overload++
+			wg.Done()
+		}
+	}
+	wg.Wait()
+
+	if overload == 0 {
+		t.Fatal("no submitter was turned away")
+	}
+}
+
+// dropTasks submits 10 named tasks to the saturated p and returns the names
+// of those which ran and of those dropped, as told to the OnDrop of the
+// policy through drops.
+func dropTasks(t *testing.T, p *Pool, drops chan DroppedTask) (ran, dropped map[string]bool) {
+	release := saturate(p)
+
+	var mu sync.Mutex
+	ran = make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("task%d", i)
+		err := p.Submit(func() {
+			mu.Lock()
+			ran[name] = true
+			mu.Unlock()
+		}, WithTaskName(name))
+		if err != nil {
+			t.Fatalf("Submit() = %v", err)
+		}
+	}
+	release()
+	time.Sleep(50 * time.Millisecond)
+
+	dropped = make(map[string]bool)
+	for len(drops) > 0 {
+		dropped[(<-drops).Name] = true
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dropped) == 0 || len(ran)+len(dropped) != 10 || p.Dropped() != int64(len(dropped)) {
+		t.Fatalf("%d tasks ran and %d dropped, %d by the count, want 10 in all", len(ran), len(dropped), p.Dropped())
+	}
+	for name := range dropped {
+		if ran[name] {
+			t.Fatalf("dropped task %s ran", name)
+		}
+	}
+	return ran, dropped
+}
+
+func TestDiscardOldestPolicyOnDrop(t *testing.T) {
+	drops := make(chan DroppedTask, 10)
+	p, _ := NewPool(1, WithQueueCap(1), WithRejectionPolicy(DiscardOldestPolicy{
+		OnDrop: func(t DroppedTask) { drops <- t },
+	}))
+	defer p.Close()
+	p.Tune(1)
+
+	ran, _ := dropTasks(t, p, drops)
+	if !ran["task9"] {
+		t.Fatal("the newest task was dropped")
+	}
+}
+
+func TestDiscardNewestPolicy(t *testing.T) {
+	drops := make(chan DroppedTask, 10)
+	p, _ := NewPool(1, WithQueueCap(1), WithRejectionPolicy(DiscardNewestPolicy{
+		OnDrop: func(t DroppedTask) { drops <- t },
+	}))
+	defer p.Close()
+	p.Tune(1)
+
+	ran, dropped := dropTasks(t, p, drops)
+	if !ran["task0"] || !dropped["task9"] {
+		t.Fatal("the oldest task was dropped, or the newest ran")
+	}
+
+	f := SubmitResult(p, func() (int, error) { return 1, nil })
+	if _, err := f.Get(context.Background()); err != nil {
+		t.Fatalf("future = %v with room in the queue", err)
+	}
+}
+
+func TestDiscardNewestPolicyArg(t *testing.T) {
+	drops := make(chan DroppedTask, 10)
+	block := make(chan struct{})
+	p, _ := NewPoolWithFunc(1, func(int) { <-block }, WithQueueCap(1),
+		WithRejectionPolicy(DiscardNewestPolicy{OnDrop: func(t DroppedTask) { drops <- t }}))
+	defer p.Close()
+	p.Tune(1)
+	defer close(block)
+
+	for i := 0; i < 4; i++ {
+		_ = p.Invoke(i)
+	}
+	if d := <-drops; d.Task != 3 && d.Task != 2 {
+		t.Fatalf("dropped argument %v, want one of the newest", d.Task)
+	}
+	// a discarded task is not also counted as submitted
+	if s := p.Stats(); s.Submitted+s.Dropped != 4 {
+		t.Fatalf("%d submitted and %d dropped of 4 tasks", s.Submitted, s.Dropped)
+	}
+}
+
+// discardErrPolicy discards the task, then fails it as well.
+type discardErrPolicy struct{}
+
+func (discardErrPolicy) Reject(r Rejection) error {
+	r.Discard()
+	return ErrQueueFull
+}
+
+func TestDiscardThenFail(t *testing.T) {
+	p, _ := NewPool(1, WithQueueCap(1), WithRejectionPolicy(discardErrPolicy{}))
+	defer p.Close()
+	p.Tune(1)
+
+	release := saturate(p)
+	defer release()
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		f := SubmitResult(p, func() (int, error) { return 1, nil })
+		select {
+		case <-f.Done():
+			_, err = f.Get(context.Background())
+		default:
+		}
+	}
+	if err != ErrTaskDropped {
+		t.Fatalf("err = %v, want ErrTaskDropped", err)
+	}
+}
+
+func TestWithQueueFullHandler(t *testing.T) {
+	rejected := make(chan RejectedTask, 2)
+	p, _ := NewPool(1, WithQueueCap(1), WithNonblocking(true),
+		WithQueueFullHandler(func(t RejectedTask) { rejected <- t }))
+	defer p.Close()
+	p.Tune(1)
+
+	release := saturate(p)
+	defer release()
+	_ = p.Submit(func() {}, WithTaskName("queued"))
+
+	diverted := false
+	if err := p.Submit(func() { diverted = true }, WithTaskName("full")); err != ErrQueueFull {
+		t.Fatalf("Submit() = %v, want ErrQueueFull", err)
+	}
+	r := <-rejected
+	if r.Name != "full" || r.Err != ErrQueueFull || r.Stats.Rejected != 1 || r.Stats.Waiting != 1 {
+		t.Fatalf("rejected %+v, want full with ErrQueueFull, 1 rejected and 1 waiting", r)
+	}
+	r.Task.(func())()
+	if !diverted {
+		t.Fatal("the rejected task didn't run when diverted")
+	}
+
+	if p.TrySubmit(func() {}, WithTaskName("try")) {
+		t.Fatal("TrySubmit() to the full queue succeeded")
+	}
+	if r := <-rejected; r.Name != "try" || r.Stats.Rejected != 2 {
+		t.Fatalf("rejected %+v by TrySubmit, want try with 2 rejected", r)
+	}
+}
+
+func TestBlockPolicyContext(t *testing.T) {
+	p, _ := NewPool(1, WithQueueCap(1), WithRejectionPolicy(BlockPolicy{}))
+	defer p.Close()
+	p.Tune(1)
+
+	release := saturate(p)
+	defer release()
+	// fill the queue, and again once the feeder took a task out of it
+	for p.TrySubmit(func() {}) {
+	}
+	time.Sleep(10 * time.Millisecond)
+	for p.TrySubmit(func() {}) {
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- p.SubmitWithContext(ctx, func(context.Context) {
+			t.Error("abandoned task ran")
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errc:
+		if err != context.Canceled {
+			t.Fatalf("SubmitWithContext() = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SubmitWithContext blocked after its context was canceled")
+	}
+}
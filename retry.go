@@ -0,0 +1,90 @@
+package tinyPool
+
+import (
+	"math/rand"
+	"time"
+)
+
+// maxBackoffShift caps the doubling of the retry backoff.
+const maxBackoffShift = 30
+
+// WithRetry runs a task which returns an error up to maxAttempts times.
+// Each retry is queued again after a backoff, which starts at backoff,
+// doubles with every attempt and is jittered by up to half its length.
+// Only the error of the last attempt is reported. A task still to be
+// retried when the pool closes is not, it settles with the error of its
+// last attempt.
+func WithRetry(maxAttempts int, backoff time.Duration) TaskOption {
+	return func(j *job) {
+		j.maxAttempts = maxAttempts
+		j.backoff = backoff
+	}
+}
+
+// retry schedules the next attempt of the task j which failed with err.
+// It returns false if j has no attempts left or the pool is closed.
+func (p *Pool) retry(j job, err error) bool {
+	j.attempt++
+	if j.attempt >= j.maxAttempts || p.IsClosed() {
+		return false
+	}
+
+	// if the pool closes first, settle with the error of the last run
+	settle := func() {
+		p.deadLetter(j, j.attempt, err)
+		if j.finish != nil {
+			j.finish()
+		}
+	}
+	p.wheel.scheduleOrDrop(retryDelay(j.backoff, j.attempt), 0, func() {
+		if p.submit(j) != nil {
+			settle()
+		}
+	}, settle)
+	return true
+}
+
+// DeadLetter is a task which failed on its last attempt.
+type DeadLetter struct {
+	// Name and Tag are the name and tag given to the task.
+	Name string
+	Tag  string
+
+	// Task is the submitted task, it may be submitted again to replay it.
+	Task func() error
+
+	// Attempts is the number of times the task ran.
+	Attempts int
+
+	// Err is the error returned by the last attempt.
+	Err error
+}
+
+// deadLetter passes the task j which failed attempts times to the
+// dead-letter handler.
+func (p *Pool) deadLetter(j job, attempts int, err error) {
+	if p.options.DeadLetterHandler == nil {
+		return
+	}
+
+	p.options.DeadLetterHandler(DeadLetter{
+		Name:     j.name,
+		Tag:      j.tag,
+		Task:     j.fnErr,
+		Attempts: attempts,
+		Err:      err,
+	})
+}
+
+// retryDelay returns the jittered backoff before the given attempt.
+func retryDelay(backoff time.Duration, attempt int) time.Duration {
+	shift := attempt - 1
+	if shift > maxBackoffShift {
+		shift = maxBackoffShift
+	}
+	d := backoff << shift
+	if half := int64(d / 2); half > 0 {
+		d = time.Duration(half + rand.Int63n(half))
+	}
+	return d
+}
@@ -0,0 +1,106 @@
+package tinyPool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRetry(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	var runs int32
+	f := SubmitResult(p, func() (int32, error) {
+		n := atomic.AddInt32(&runs, 1)
+		if n < 3 {
+			return 0, errors.New("flaky")
+		}
+		return n, nil
+	}, WithRetry(5, time.Millisecond))
+
+	n, err := f.Get(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("got %d, %v, want success on the third run", n, err)
+	}
+}
+
+func TestWithRetryExhausted(t *testing.T) {
+	reported := make(chan error, 4)
+	p, _ := NewPool(1, WithErrorHandler(func(err error) {
+		reported <- err
+	}))
+	defer p.Close()
+
+	var runs int32
+	fail := errors.New("down")
+	_ = p.SubmitErr(func() error {
+		atomic.AddInt32(&runs, 1)
+		return fail
+	}, WithRetry(3, time.Millisecond))
+
+	select {
+	case err := <-reported:
+		if err != fail {
+			t.Fatalf("reported %v, want %v", err, fail)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("error of the last attempt not reported")
+	}
+	if n := atomic.LoadInt32(&runs); n != 3 {
+		t.Fatalf("ran %d times, want 3", n)
+	}
+}
+
+func TestWithRetryClosed(t *testing.T) {
+	p, _ := NewPool(1)
+
+	ran := make(chan struct{})
+	fail := errors.New("down")
+	f := SubmitResult(p, func() (int, error) {
+		close(ran)
+		return 0, fail
+	}, WithRetry(3, time.Hour))
+	<-ran
+	p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := f.Get(ctx); err != fail {
+		t.Fatalf("Get() = %v after close, want the error of the last run", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := 1; attempt < 5; attempt++ {
+		full := 10 * time.Millisecond << (attempt - 1)
+		d := retryDelay(10*time.Millisecond, attempt)
+		if d < full/2 || d >= full {
+			t.Fatalf("delay of attempt %d = %v, want in [%v, %v)", attempt, d, full/2, full)
+		}
+	}
+}
+
+func TestWithDeadLetterHandler(t *testing.T) {
+	letters := make(chan DeadLetter, 1)
+	p, _ := NewPool(1, WithDeadLetterHandler(func(l DeadLetter) {
+		letters <- l
+	}))
+	defer p.Close()
+
+	fail := errors.New("down")
+	_ = p.SubmitErr(func() error {
+		return fail
+	}, WithRetry(2, time.Millisecond), WithTaskName("sync"))
+
+	select {
+	case l := <-letters:
+		if l.Name != "sync" || l.Attempts != 2 || l.Err != fail || l.Task == nil {
+			t.Fatalf("dead letter = %+v", l)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("exhausted task not dead-lettered")
+	}
+}
@@ -0,0 +1,80 @@
+package tinyPool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ScalePolicy decides whether a submitter starts another worker for its
+// task, once the pool runs its core size and until it reaches capacity.
+type ScalePolicy interface {
+	// Grow reports whether to start a worker in the state s.
+	Grow(s ScaleState) bool
+}
+
+// ScaleState is the load of the pool a ScalePolicy decides on.
+type ScaleState struct {
+	Running int
+	Idle    int
+
+	// Queued is the number of tasks waiting for a worker, Wait how long
+	// the latest started task waited.
+	Queued int
+	Wait   time.Duration
+}
+
+// EagerScalePolicy starts a worker for every task, the default without a
+// core size.
+type EagerScalePolicy struct{}
+
+// Grow implements ScalePolicy.
+func (EagerScalePolicy) Grow(s ScaleState) bool {
+	return true
+}
+
+// QueueScalePolicy starts workers only while more than Depth tasks are
+// queued, or tasks wait for longer than Wait to start, so a spike which the
+// running workers get through soon doesn't start and expire new ones. Wait
+// 0 is ignored.
+type QueueScalePolicy struct {
+	Depth int
+	Wait  time.Duration
+}
+
+// Grow implements ScalePolicy.
+func (q QueueScalePolicy) Grow(s ScaleState) bool {
+	return s.Queued > q.Depth || (q.Wait > 0 && s.Wait > q.Wait)
+}
+
+// growLimit returns the number of workers a submitter may start: the limit
+// while the scale policy grows the pool, else the core size. A pool with a
+// policy starts at least one worker.
+func (p *engine[T]) growLimit() int32 {
+	policy := p.options.ScalePolicy
+	core := int32(p.options.CoreSize)
+	if policy == nil {
+		if core <= 0 {
+			return p.limit()
+		}
+		policy = QueueScalePolicy{Depth: p.options.GrowThreshold}
+	}
+	if core < 1 {
+		core = 1
+	}
+
+	limit := p.limit()
+	if core >= limit || p.Running() < core || policy.Grow(p.scaleState()) {
+		return limit
+	}
+	return core
+}
+
+// scaleState returns the current load of the pool.
+func (p *engine[T]) scaleState() ScaleState {
+	return ScaleState{
+		Running: int(p.Running()),
+		Idle:    int(atomic.LoadInt32(&p.idle)),
+		Queued:  p.Waiting(),
+		Wait:    time.Duration(atomic.LoadInt64(&p.lastWait)),
+	}
+}
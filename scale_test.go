@@ -0,0 +1,106 @@
+package tinyPool
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestQueueScalePolicy(t *testing.T) {
+	policy := QueueScalePolicy{Depth: 4, Wait: 10 * time.Millisecond}
+	tests := []struct {
+		state ScaleState
+		grow  bool
+	}{
+		{ScaleState{Queued: 4}, false},
+		{ScaleState{Queued: 5}, true},
+		{ScaleState{Queued: 1, Wait: 5 * time.Millisecond}, false},
+		{ScaleState{Queued: 1, Wait: 20 * time.Millisecond}, true},
+	}
+	for _, test := range tests {
+		if grow := policy.Grow(test.state); grow != test.grow {
+			t.Errorf("Grow(%+v) = %v, want %v", test.state, grow, test.grow)
+		}
+	}
+}
+
+func TestWithScalePolicy(t *testing.T) {
+	p, _ := NewPool(8, WithScalePolicy(QueueScalePolicy{Depth: 2}))
+	defer p.Close()
+
+	block := make(chan struct{})
+	defer close(block)
+	for i := 0; i < 4; i++ {
+		_ = p.Submit(func() { <-block })
+	}
+	if n := p.Running(); n != 1 {
+		t.Fatalf("running = %d with 3 tasks queued, want 1", n)
+	}
+
+	_ = p.Submit(func() { <-block })
+	if n := p.Running(); n != 2 {
+		t.Fatalf("running = %d with the queue past the depth, want 2", n)
+	}
+}
+
+func TestScaleOnWait(t *testing.T) {
+	p, _ := NewPool(8, WithScalePolicy(QueueScalePolicy{Depth: 100, Wait: 10 * time.Millisecond}))
+	defer p.Close()
+
+	block := make(chan struct{})
+	defer close(block)
+	started := make(chan struct{})
+	_ = p.Submit(func() { time.Sleep(50 * time.Millisecond) })
+	_ = p.Submit(func() { close(started); <-block })
+
+	// the second task waited for the first one
+	<-started
+	_ = p.Submit(func() { <-block })
+	if n := p.Running(); n != 2 {
+		t.Fatalf("running = %d after a long queue wait, want 2", n)
+	}
+}
+
+func TestScaleToZero(t *testing.T) {
+	before := runtime.NumGoroutine()
+	p, _ := NewPool(4, WithScaleToZero(), WithExpiry(10*time.Millisecond))
+	defer p.Close()
+
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("new pool runs %d goroutines before its first task", n-before)
+	}
+
+	for i := 0; i < 2; i++ {
+		done := make(chan struct{})
+		_ = p.Submit(func() { close(done) })
+		<-done
+
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				t.Fatalf("idle pool still runs %d goroutines", runtime.NumGoroutine()-before)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		if n := p.Running(); n != 0 {
+			t.Fatalf("running = %d, want 0", n)
+		}
+	}
+}
+
+func TestScaleToZeroDrain(t *testing.T) {
+	p, _ := NewPool(1, WithScaleToZero())
+	if tasks := p.Drain(); len(tasks) != 0 {
+		t.Fatalf("drained %d tasks from an unused pool", len(tasks))
+	}
+	p.Reboot()
+	defer p.Close()
+
+	done := make(chan struct{})
+	_ = p.Submit(func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("task not run after Reboot")
+	}
+}
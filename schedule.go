@@ -0,0 +1,54 @@
+package tinyPool
+
+import (
+	"errors"
+	"time"
+)
+
+// ScheduledTask is a handle to a task scheduled to run on the pool later.
+type ScheduledTask struct {
+	w *timingWheel
+	t *timer
+}
+
+// Stop cancels the scheduled task. It returns false if the task has
+// already been stopped, or submitted when it does not recur.
+func (s *ScheduledTask) Stop() bool {
+	return s.w.stop(s.t)
+}
+
+// SubmitAfter submits task to the pool once d has elapsed. The task is
+// dropped if the pool is closed by then.
+func (p *Pool) SubmitAfter(d time.Duration, task func(), opts ...TaskOption) (*ScheduledTask, error) {
+	if p.IsClosed() {
+		return nil, ErrPoolClosed
+	}
+
+	t := p.wheel.schedule(d, 0, func() {
+		_ = p.Submit(task, opts...)
+	})
+	return &ScheduledTask{w: p.wheel, t: t}, nil
+}
+
+// SubmitAt submits task to the pool at t. The task is dropped if the pool
+// is closed by then.
+func (p *Pool) SubmitAt(t time.Time, task func(), opts ...TaskOption) (*ScheduledTask, error) {
+	return p.SubmitAfter(time.Until(t), task, opts...)
+}
+
+// Every submits task to the pool every interval until the returned
+// ScheduledTask is stopped or the pool is closed. A run is submitted even
+// if the previous one is still running.
+func (p *Pool) Every(interval time.Duration, task func(), opts ...TaskOption) (*ScheduledTask, error) {
+	if interval <= 0 {
+		return nil, errors.New("non-positive interval for Every")
+	}
+	if p.IsClosed() {
+		return nil, ErrPoolClosed
+	}
+
+	t := p.wheel.schedule(interval, interval, func() {
+		_ = p.Submit(task, opts...)
+	})
+	return &ScheduledTask{w: p.wheel, t: t}, nil
+}
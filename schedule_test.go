@@ -0,0 +1,78 @@
+package tinyPool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubmitAfter(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	start := time.Now()
+	done := make(chan time.Duration, 1)
+	if _, err := p.SubmitAfter(20*time.Millisecond, func() {
+		done <- time.Since(start)
+	}); err != nil {
+		t.Fatalf("SubmitAfter() = %v", err)
+	}
+
+	select {
+	case d := <-done:
+		if d < 20*time.Millisecond {
+			t.Fatalf("task ran after %v, want at least 20ms", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("delayed task did not run")
+	}
+}
+
+func TestSubmitAtStop(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	ran := make(chan struct{})
+	s, _ := p.SubmitAt(time.Now().Add(20*time.Millisecond), func() {
+		close(ran)
+	})
+	if !s.Stop() {
+		t.Fatal("Stop() = false for a pending task")
+	}
+
+	select {
+	case <-ran:
+		t.Fatal("stopped task ran")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEvery(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	ticks := make(chan struct{}, 10)
+	s, err := p.Every(5*time.Millisecond, func() {
+		ticks <- struct{}{}
+	})
+	if err != nil {
+		t.Fatalf("Every() = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ticks:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d runs of the recurring task", i)
+		}
+	}
+
+	s.Stop()
+	time.Sleep(20 * time.Millisecond)
+	for len(ticks) > 0 {
+		<-ticks
+	}
+	time.Sleep(20 * time.Millisecond)
+	if len(ticks) > 0 {
+		t.Fatal("recurring task still runs after Stop")
+	}
+}
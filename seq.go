@@ -0,0 +1,67 @@
+//go:build go1.23
+
+package tinyPool
+
+import (
+	"context"
+	"iter"
+)
+
+// MapSeq returns a sequence of fn applied to the values of seq. The values
+// are processed on p, at most the capacity of p at once, and the results
+// are yielded in completion order. Stopping the iteration early stops
+// reading seq. If p refuses a task, its error is yielded last.
+func MapSeq[T, R any](p *Pool, seq iter.Seq[T], fn func(T) (R, error)) iter.Seq2[R, error] {
+	return func(yield func(R, error) bool) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		in := make(chan T)
+		go func() {
+			defer close(in)
+			for v := range seq {
+				select {
+				case in <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		out := make(chan Result[R])
+		go func() {
+			defer close(out)
+			process(ctx, p, in, int(p.Cap()), out, func(_ context.Context, v T) (Result[R], error) {
+				val, err := fn(v)
+				return Result[R]{Val: val, Err: err}, nil
+			}, func(err error) {
+				select {
+				case out <- Result[R]{Err: err}:
+				case <-ctx.Done():
+				}
+			})
+		}()
+
+		for r := range out {
+			if !yield(r.Val, r.Err) {
+				cancel()
+				for range out {
+				}
+				return
+			}
+		}
+	}
+}
+
+// ForEachSeq runs fn on every value of seq on p and returns the first
+// error, after which no further value is read.
+func ForEachSeq[T any](p *Pool, seq iter.Seq[T], fn func(T) error) error {
+	for _, err := range MapSeq(p, seq, func(v T) (struct{}, error) {
+		return struct{}{}, fn(v)
+	}) {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+//go:build go1.23
+
+package tinyPool
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestMapSeq(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	sum := 0
+	for n, err := range MapSeq(p, slices.Values([]int{1, 2, 3, 4}), func(n int) (int, error) {
+		return n * n, nil
+	}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum += n
+	}
+	if sum != 30 {
+		t.Fatalf("sum = %d, want 30", sum)
+	}
+}
+
+func TestForEachSeq(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	boom := errors.New("boom")
+	infinite := func(yield func(int) bool) {
+		for i := 0; yield(i); i++ {
+		}
+	}
+	err := ForEachSeq(p, infinite, func(n int) error {
+		if n == 10 {
+			return boom
+		}
+		return nil
+	})
+	if err != boom {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+}
@@ -0,0 +1,230 @@
+package tinyPool
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// shard is a task queue with its own feeder goroutine, which hands the
+// queued tasks to workers. Spreading submissions over several shards, see
+// WithDispatchShards, keeps a single queue and feeder from capping the
+// throughput of tiny tasks.
+type shard[T any] struct {
+	q Queue
+
+	// ready wakes the feeder up after a push to the empty queue
+	ready chan struct{}
+
+	// task hands tasks to the workers of the shard, other workers take
+	// them from the engine task channel
+	task chan T
+
+	// held is the number of tasks popped by the feeder in its current
+	// batch, which it has yet to hand out
+	held int64
+
+	// leftover holds the tasks the feeder was handing out when the pool quit
+	leftover []T
+
+	// codel is the controlled delay state of the queue
+	codel codel
+
+	// deadline fires when the task the feeder is handing out expires,
+	// created on the first task with a deadline
+	deadline *time.Timer
+}
+
+// dispatchBatch is the max number of tasks a feeder pops at once.
+const dispatchBatch = 64
+
+// batchQueue is a Queue which pops several values at once cheaper than one
+// by one.
+type batchQueue interface {
+	// popBatch pops up to len(buf) values into buf and returns their
+	// number.
+	popBatch(buf []interface{}) int
+}
+
+// urgentQueue is a batchQueue whose values pushed after a batch may have
+// to run before the rest of it.
+type urgentQueue interface {
+	// popUrgent pops a value more urgent than the rest of the last batch,
+	// nil if there is none.
+	popUrgent() interface{}
+}
+
+// popBatch pops up to len(buf) values from q into buf, a single one unless
+// q is a batchQueue.
+func popBatch(q Queue, buf []interface{}) int {
+	if b, ok := q.(batchQueue); ok {
+		return b.popBatch(buf)
+	}
+	if v := q.Pop(); v != nil {
+		buf[0] = v
+		return 1
+	}
+	return 0
+}
+
+// newShards returns the shards of the engine, the first one using the
+// queue set in the options.
+func (p *engine[T]) newShards() []*shard[T] {
+	n := p.options.DispatchShards
+	if n < 1 || p.options.shardQueue == nil {
+		n = 1
+	}
+
+	shards := make([]*shard[T], n)
+	for i := range shards {
+		s := &shard[T]{q: p.options.Queue, ready: make(chan struct{}, 1), task: p.task}
+		if i > 0 {
+			s.q = p.options.shardQueue()
+		}
+		if n > 1 {
+			s.task = make(chan T)
+		}
+		shards[i] = s
+	}
+	return shards
+}
+
+// pick returns the shard for the next push.
+func (p *engine[T]) pick() *shard[T] {
+	if len(p.shards) == 1 {
+		return p.shards[0]
+	}
+	return p.shards[atomic.AddUint32(&p.nextPush, 1)%uint32(len(p.shards))]
+}
+
+// feed hands the tasks queued in s to workers until the pool quits, or
+// sleep is closed while the queue is empty. It pops them in batches, to
+// take the queue synchronization once per batch.
+func (p *engine[T]) feed(s *shard[T], sleep chan struct{}) {
+	batch := make([]interface{}, dispatchBatch)
+	urgent, _ := s.q.(urgentQueue)
+	for {
+		select {
+		case <-p.quitSig:
+			return
+		default:
+		}
+
+		if s.q.Size() == 0 {
+			// sleep until the next push
+			select {
+			case <-s.ready:
+				continue
+			case <-p.quitSig:
+				return
+			case <-sleep:
+				return
+			}
+		}
+
+		n := popBatch(s.q, batch)
+		if n == 0 {
+			// a push is still linking its item in
+			runtime.Gosched()
+			continue
+		}
+		atomic.StoreInt64(&s.held, int64(n))
+		for i := 0; i < n; {
+			var item T
+			if v := popUrgent(urgent, i); v != nil {
+				// pushed after the batch, it goes ahead of the rest
+				item = p.unbox(v)
+			} else {
+				item = p.unbox(batch[i])
+				batch[i] = nil
+				i++
+				atomic.AddInt64(&s.held, -1)
+			}
+			p.backlog()
+			if !p.dequeued() {
+				p.discardOldest(item)
+				continue
+			}
+			if p.overdue(s, item) || p.dropStale(item) {
+				continue
+			}
+			select {
+			case s.task <- item:
+			case p.task <- item:
+			case <-p.expiry(s, item):
+				// expired while all workers were busy
+				atomic.AddInt64(&p.pending, -1)
+				p.expire(item)
+			case <-p.quitSig:
+				s.leftover = append(s.leftover, item)
+				p.keep(s, batch[i:n])
+				return
+			}
+		}
+	}
+}
+
+// popUrgent pops a value of q to hand out before the rest of the batch,
+// from which i values have been handed out.
+func popUrgent(q urgentQueue, i int) interface{} {
+	if q == nil || i == 0 {
+		return nil
+	}
+	return q.popUrgent()
+}
+
+// keep moves the rest of the batch of s to its leftover when the pool
+// quits.
+func (p *engine[T]) keep(s *shard[T], rest []interface{}) {
+	for i, v := range rest {
+		item := p.unbox(v)
+		rest[i] = nil
+		if p.dequeued() {
+			s.leftover = append(s.leftover, item)
+		} else {
+			p.discardOldest(item)
+		}
+	}
+	atomic.StoreInt64(&s.held, 0)
+}
+
+// box returns item in a box for the queue, as storing a task itself in an
+// interface allocates. The boxes are recycled by unbox.
+func (p *engine[T]) box(item T) *T {
+	b := p.boxes.Get().(*T)
+	*b = item
+	return b
+}
+
+// unbox returns the task in the box v popped from the queue.
+func (p *engine[T]) unbox(v interface{}) T {
+	b := v.(*T)
+	item := *b
+	var zero T
+	*b = zero
+	p.boxes.Put(b)
+	return item
+}
+
+// dequeued releases the queue slot of a task taken out of the queue. It
+// reports false if the task has to be dropped to make room for a task
+// queued by DiscardOldest instead.
+func (p *engine[T]) dequeued() bool {
+	if p.dropOldest() {
+		return false
+	}
+	if p.slots != nil {
+		<-p.slots
+	}
+	return true
+}
+
+// queued returns the number of tasks in all shards, including the batches
+// popped by the feeders.
+func (p *engine[T]) queued() int64 {
+	var n int64
+	for _, s := range p.shards {
+		n += s.q.Size() + atomic.LoadInt64(&s.held)
+	}
+	return n
+}
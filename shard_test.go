@@ -0,0 +1,65 @@
+package tinyPool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDispatchShards(t *testing.T) {
+	p, _ := NewPool(4, WithDispatchShards(4))
+	defer p.Close()
+
+	if len(p.shards) != 4 {
+		t.Fatalf("%d shards, want 4", len(p.shards))
+	}
+
+	var ran int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				_ = p.Submit(func() { atomic.AddInt64(&ran, 1) })
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran != 8000 {
+		t.Fatalf("%d tasks ran, want 8000", ran)
+	}
+}
+
+func TestDispatchShardsDrain(t *testing.T) {
+	p, _ := NewPool(1, WithDispatchShards(3))
+	defer p.Close()
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	_ = p.Submit(func() { close(started); <-block })
+	<-started
+
+	for i := 0; i < 9; i++ {
+		_ = p.Submit(func() {})
+	}
+	tasks := p.Drain()
+	close(block)
+	if len(tasks) != 9 {
+		t.Fatalf("drained %d tasks, want 9", len(tasks))
+	}
+}
+
+func TestDispatchShardsCustomQueue(t *testing.T) {
+	p, _ := NewPoolWithFunc(1, func(int) {}, WithQueue(newMpscQueue()), WithDispatchShards(4))
+	defer p.Close()
+
+	if len(p.shards) != 1 {
+		t.Fatalf("%d shards with a custom queue, want 1", len(p.shards))
+	}
+}
@@ -0,0 +1,53 @@
+package tinyPool
+
+import (
+	"fmt"
+	"time"
+)
+
+// SinkMode decides what SubmitTo does with a result while its channel is
+// full.
+type SinkMode int
+
+const (
+	// SinkBlock makes the worker wait until the channel has room.
+	SinkBlock SinkMode = iota
+
+	// SinkDrop discards the result.
+	SinkDrop
+)
+
+// SubmitTo submits fn to p and sends its result to ch once it has run.
+// mode decides what happens if ch is full then. A panic of fn is sent as
+// an error. SubmitTo returns the error of the submission; nothing is sent
+// for a task which was not accepted.
+func SubmitTo[T any](p *Pool, ch chan<- Result[T], mode SinkMode, fn func() (T, error), opts ...TaskOption) error {
+	if fn == nil {
+		return nil
+	}
+
+	return p.Submit(func() {
+		start := time.Now()
+		send := func(r Result[T]) {
+			r.Duration = time.Since(start)
+			if mode == SinkDrop {
+				select {
+				case ch <- r:
+				default:
+				}
+				return
+			}
+			ch <- r
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				send(Result[T]{Err: fmt.Errorf("task panicked: %v", r)})
+				panic(r)
+			}
+		}()
+
+		val, err := fn()
+		send(Result[T]{Val: val, Err: err})
+	}, opts...)
+}
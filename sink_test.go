@@ -0,0 +1,57 @@
+package tinyPool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSubmitTo(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	results := make(chan Result[int], 2)
+	errBad := errors.New("bad")
+	_ = SubmitTo(p, results, SinkBlock, func() (int, error) {
+		time.Sleep(time.Millisecond)
+		return 1, nil
+	})
+	_ = SubmitTo(p, results, SinkBlock, func() (int, error) {
+		return 0, errBad
+	})
+
+	var sum int
+	var errs int
+	for i := 0; i < 2; i++ {
+		r := <-results
+		sum += r.Val
+		if r.Err == errBad {
+			errs++
+		}
+		if r.Val == 1 && r.Duration < time.Millisecond {
+			t.Fatalf("duration = %v, want at least 1ms", r.Duration)
+		}
+	}
+	if sum != 1 || errs != 1 {
+		t.Fatalf("sum = %d, errors = %d", sum, errs)
+	}
+}
+
+func TestSubmitToDrop(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	results := make(chan Result[int], 1)
+	results <- Result[int]{Val: -1}
+	_ = SubmitTo(p, results, SinkDrop, func() (int, error) {
+		return 1, nil
+	})
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if r := <-results; r.Val != -1 || len(results) != 0 {
+		t.Fatalf("result %v replaced the queued one in drop mode", r)
+	}
+}
@@ -0,0 +1,82 @@
+package tinyPool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Estimator predicts how long a task submitted now takes to finish, queue
+// wait included, for the latency SLO, see WithSLO.
+type Estimator interface {
+	Estimate(l Load) time.Duration
+}
+
+// EstimatorFunc is an Estimator calling the function.
+type EstimatorFunc func(l Load) time.Duration
+
+// Estimate implements Estimator.
+func (f EstimatorFunc) Estimate(l Load) time.Duration {
+	return f(l)
+}
+
+// Load is the state of the pool an Estimator predicts from.
+type Load struct {
+	// Queued is the number of tasks ahead of the new one, Workers the
+	// number of tasks which may run at once.
+	Queued  int
+	Workers int
+
+	// Service is the mean duration of the finished tasks, Wait the queue
+	// wait of the latest started one.
+	Service time.Duration
+	Wait    time.Duration
+}
+
+// QueueEstimator predicts that a task waits for the queued tasks to run on
+// all workers, then runs for the mean task duration. The default
+// Estimator.
+type QueueEstimator struct{}
+
+// Estimate implements Estimator.
+func (QueueEstimator) Estimate(l Load) time.Duration {
+	workers := l.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	rounds := l.Queued/workers + 1
+	return time.Duration(rounds) * l.Service
+}
+
+// load returns the state of the pool for the Estimator.
+func (p *engine[T]) load() Load {
+	workers := p.Cap()
+	if p.options.ConcurrencyLimit != nil || p.options.CPUThreshold > 0 {
+		if limit := p.runLimit(); limit < workers {
+			workers = limit
+		}
+	}
+	var service time.Duration
+	if completed := atomic.LoadInt64(&p.completed); completed > 0 {
+		service = time.Duration(atomic.LoadInt64(&p.durations.sum) / completed)
+	}
+	return Load{
+		Queued:  p.Waiting(),
+		Workers: int(workers),
+		Service: service,
+		Wait:    time.Duration(atomic.LoadInt64(&p.lastWait)),
+	}
+}
+
+// missesSLO reports whether a task submitted now is predicted to finish
+// later than the latency SLO.
+func (p *engine[T]) missesSLO() bool {
+	slo := p.options.SLO
+	if slo <= 0 {
+		return false
+	}
+	estimator := p.options.SLOEstimator
+	if estimator == nil {
+		estimator = QueueEstimator{}
+	}
+	return estimator.Estimate(p.load()) > slo
+}
@@ -0,0 +1,68 @@
+package tinyPool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueEstimator(t *testing.T) {
+	cases := []struct {
+		l    Load
+		want time.Duration
+	}{
+		{Load{Queued: 0, Workers: 2, Service: 10 * time.Millisecond}, 10 * time.Millisecond},
+		{Load{Queued: 4, Workers: 2, Service: 10 * time.Millisecond}, 30 * time.Millisecond},
+		{Load{Queued: 3, Workers: 0, Service: time.Millisecond}, 4 * time.Millisecond},
+	}
+	for _, c := range cases {
+		if got := (QueueEstimator{}).Estimate(c.l); got != c.want {
+			t.Errorf("estimate of %+v = %v, want %v", c.l, got, c.want)
+		}
+	}
+}
+
+func TestWithSLO(t *testing.T) {
+	p, _ := NewPool(1, WithSLO(25*time.Millisecond, nil))
+	defer p.Close()
+	p.Tune(1)
+
+	// the first task tells the service time
+	done := make(chan struct{})
+	_ = p.Submit(func() {
+		time.Sleep(10 * time.Millisecond)
+		close(done)
+	})
+	<-done
+	time.Sleep(5 * time.Millisecond)
+
+	var err error
+	accepted := 0
+	for ; accepted < 10; accepted++ {
+		if err = p.Submit(func() { time.Sleep(10 * time.Millisecond) }); err != nil {
+			break
+		}
+	}
+	if err != ErrWouldMissSLO || accepted < 2 || accepted > 4 {
+		t.Fatalf("submit %d = %v, want ErrWouldMissSLO after 2 or 3 tasks", accepted, err)
+	}
+	if p.TrySubmit(func() {}) {
+		t.Fatal("TrySubmit past the SLO succeeded")
+	}
+}
+
+func TestSLOEstimatorFunc(t *testing.T) {
+	var seen Load
+	p, _ := NewPool(2, WithSLO(time.Second, EstimatorFunc(func(l Load) time.Duration {
+		seen = l
+		return time.Hour
+	})))
+	defer p.Close()
+	p.Tune(2)
+
+	if err := p.Submit(func() {}); err != ErrWouldMissSLO {
+		t.Fatalf("submit = %v, want ErrWouldMissSLO", err)
+	}
+	if seen.Workers != 2 {
+		t.Fatalf("estimator saw %d workers, want 2", seen.Workers)
+	}
+}
@@ -0,0 +1,40 @@
+package tinyPool
+
+import "time"
+
+// SlowTask describes a task which has been running for longer than the
+// threshold set by WithSlowTaskThreshold.
+type SlowTask struct {
+	// Name and Tag are set by WithTaskName and WithTag.
+	Name string
+	Tag  string
+
+	// Started is when the task started, Elapsed how long it has run.
+	Started time.Time
+	Elapsed time.Duration
+}
+
+// watchSlow reports item to the slow task handler once it has run for the
+// threshold. The returned function stops watching it.
+func (p *engine[T]) watchSlow(item T, start time.Time) func() bool {
+	d, handler := p.options.SlowTaskThreshold, p.options.SlowTaskHandler
+	if d <= 0 || handler == nil {
+		return unwatchedSlow
+	}
+	return p.slowTimer(item, start, d, handler)
+}
+
+// unwatchedSlow stops watching a task without slow task threshold. Unlike
+// a func literal in a generic method, it doesn't allocate.
+func unwatchedSlow() bool { return false }
+
+// slowTimer calls handler with item once it has run for d. Split from
+// watchSlow, so item only escapes with a threshold.
+func (p *engine[T]) slowTimer(item T, start time.Time, d time.Duration, handler func(SlowTask)) func() bool {
+	t := time.AfterFunc(d, func() {
+		slow := SlowTask{Name: p.name(item), Tag: p.tag(item), Started: start, Elapsed: time.Since(start)}
+		p.log(LevelWarn, "slow task", "task", slow.Name, "tag", slow.Tag, "elapsed", slow.Elapsed)
+		handler(slow)
+	})
+	return t.Stop
+}
@@ -0,0 +1,31 @@
+package tinyPool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithSlowTaskThreshold(t *testing.T) {
+	slow := make(chan SlowTask, 2)
+	p, _ := NewPool(2, WithSlowTaskThreshold(20*time.Millisecond, func(s SlowTask) { slow <- s }))
+	defer p.Close()
+
+	release := make(chan struct{})
+	_ = p.Submit(func() {}, WithTaskName("fast"))
+	_ = p.Submit(func() { <-release }, WithTaskName("stuck"), WithTag("db"))
+
+	select {
+	case s := <-slow:
+		if s.Name != "stuck" || s.Tag != "db" || s.Elapsed < 20*time.Millisecond {
+			t.Fatalf("slow task = %+v", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slow task not reported while running")
+	}
+	close(release)
+
+	time.Sleep(50 * time.Millisecond)
+	if len(slow) != 0 {
+		t.Fatalf("reported %+v too", <-slow)
+	}
+}
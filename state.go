@@ -0,0 +1,42 @@
+package tinyPool
+
+// WorkerState is passed to the tasks submitted by SubmitWithState. It
+// belongs to the worker running the task, so tasks may use it without
+// locking.
+type WorkerState struct {
+	// ID is the worker ID shown by DumpWorkers.
+	ID int
+
+	// Value is created for the worker by the factory set with
+	// WithWorkerState, nil without one.
+	Value interface{}
+}
+
+// newWorkerState creates the state of the worker id.
+func (p *engine[T]) newWorkerState(id int) *WorkerState {
+	ws := &WorkerState{ID: id}
+	if p.options.WorkerStateFactory != nil {
+		ws.Value = p.options.WorkerStateFactory()
+	}
+	return ws
+}
+
+// releaseWorkerState releases the state of an exiting worker.
+func (p *engine[T]) releaseWorkerState(ws *WorkerState) {
+	if p.options.WorkerStateRelease != nil && ws.Value != nil {
+		p.options.WorkerStateRelease(ws.Value)
+	}
+}
+
+// SubmitWithState submits a task which gets the state of the worker
+// running it, such as a connection or a scratch buffer created once per
+// worker by the factory set with WithWorkerState.
+func (p *Pool) SubmitWithState(task func(ws WorkerState), opts ...TaskOption) error {
+	if task == nil {
+		return nil
+	}
+
+	j := newJob(opts)
+	j.fnState = task
+	return p.submit(j)
+}
@@ -0,0 +1,92 @@
+package tinyPool
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubmitWithState(t *testing.T) {
+	var created, released int32
+	p, _ := NewPool(2, WithWorkerState(func() interface{} {
+		atomic.AddInt32(&created, 1)
+		return new([]int)
+	}, func(v interface{}) {
+		atomic.AddInt32(&released, 1)
+	}))
+
+	var mu sync.Mutex
+	owners := make(map[*[]int]int)
+	var wg sync.WaitGroup
+	wg.Add(100)
+	for i := 0; i < 100; i++ {
+		i := i
+		_ = p.SubmitWithState(func(ws WorkerState) {
+			defer wg.Done()
+			buf := ws.Value.(*[]int)
+			*buf = append(*buf, i)
+			mu.Lock()
+			if id, ok := owners[buf]; ok && id != ws.ID {
+				t.Errorf("state of worker %d passed to worker %d", id, ws.ID)
+			}
+			owners[buf] = ws.ID
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	p.Close()
+
+	n := 0
+	for buf := range owners {
+		n += len(*buf)
+	}
+	if n != 100 {
+		t.Fatalf("states saw %d tasks, want 100", n)
+	}
+	if c, r := atomic.LoadInt32(&created), atomic.LoadInt32(&released); c != r || int(c) < len(owners) {
+		t.Fatalf("created %d states, released %d, used %d", c, r, len(owners))
+	}
+}
+
+func TestWithWorkerInit(t *testing.T) {
+	var inits, teardowns int32
+	p, _ := NewPool(4, WithPreAlloc(3), WithExpiry(10*time.Millisecond),
+		WithWorkerInit(func() error {
+			if atomic.AddInt32(&inits, 1) == 1 {
+				return errors.New("no connection")
+			}
+			return nil
+		}),
+		WithWorkerTeardown(func() { atomic.AddInt32(&teardowns, 1) }))
+	defer p.Close()
+
+	time.Sleep(200 * time.Millisecond)
+	if n := p.Running(); n != 0 {
+		t.Fatalf("running = %d after expiry, want 0", n)
+	}
+	if i, td := atomic.LoadInt32(&inits), atomic.LoadInt32(&teardowns); i != 3 || td != 2 {
+		t.Fatalf("inits = %d, teardowns = %d, want 3 and 2", i, td)
+	}
+}
+
+func TestWithMaxTasksPerWorker(t *testing.T) {
+	p, _ := NewPool(1, WithMaxTasksPerWorker(2))
+	defer p.Close()
+	p.Tune(1)
+
+	var ids []int
+	for i := 0; i < 6; i++ {
+		done := make(chan int)
+		_ = p.SubmitWithState(func(ws WorkerState) { done <- ws.ID })
+		ids = append(ids, <-done)
+	}
+	if ids[0] != ids[1] || ids[1] == ids[2] || ids[2] != ids[3] || ids[3] == ids[4] || ids[4] != ids[5] {
+		t.Fatalf("tasks ran on workers %v, want 2 per worker", ids)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := p.Running(); n != 1 {
+		t.Fatalf("running = %d, want the last worker replaced", n)
+	}
+}
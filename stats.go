@@ -0,0 +1,86 @@
+package tinyPool
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the state of a pool.
+type Stats struct {
+	// Cap is the capacity, Running the number of started workers, Idle
+	// the number of those waiting for a task and Free the number of
+	// workers which may still be started.
+	Cap     int
+	Running int
+	Idle    int
+	Free    int
+
+	// Stuck is the number of workers running a task for longer than the
+	// limit set by WithStuckWorkerLimit.
+	Stuck int
+
+	// Waiting is the number of queued tasks.
+	Waiting int
+
+	// Limit is the number of tasks which may run at once by the
+	// concurrency limit and the CPU governor, 0 without either, and
+	// Inflight the number of tasks running under it.
+	Limit    int
+	Inflight int
+
+	// Submitted is the number of accepted tasks, Completed the number of
+	// finished ones and Rejected the number of refused submissions.
+	// Dropped is the number of tasks dropped by CoDel or the rejection
+	// policy.
+	Submitted int64
+	Completed int64
+	Rejected  int64
+	Dropped   int64
+
+	// P50, P95 and P99 are percentiles of the task durations, accurate to
+	// about 1/8 of their value.
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+
+	// WaitP50, WaitP95 and WaitP99 are percentiles of the time tasks
+	// waited between their submission and their start. Waits growing
+	// while workers are all busy mean the pool is too small.
+	WaitP50 time.Duration
+	WaitP95 time.Duration
+	WaitP99 time.Duration
+}
+
+// Stats returns a snapshot of the state of the pool. Its fields are read
+// one by one, so they may be slightly inconsistent under load.
+func (p *engine[T]) Stats() Stats {
+	q := p.latencies.quantiles(0.5, 0.95, 0.99)
+	w := p.waits.quantiles(0.5, 0.95, 0.99)
+	var limit int
+	if p.options.ConcurrencyLimit != nil || p.options.CPUThreshold > 0 {
+		if limit = int(p.runLimit()); limit == math.MaxInt32 {
+			limit = 0
+		}
+	}
+	return Stats{
+		Cap:       int(p.Cap()),
+		Running:   int(p.Running()),
+		Idle:      int(p.Idle()),
+		Free:      int(p.Free()),
+		Stuck:     int(p.Stuck()),
+		Waiting:   p.Waiting(),
+		Limit:     limit,
+		Inflight:  int(atomic.LoadInt32(&p.inflight)),
+		Submitted: atomic.LoadInt64(&p.submitted),
+		Completed: atomic.LoadInt64(&p.completed),
+		Rejected:  atomic.LoadInt64(&p.rejected),
+		Dropped:   atomic.LoadInt64(&p.dropped),
+		P50:       q[0],
+		P95:       q[1],
+		P99:       q[2],
+		WaitP50:   w[0],
+		WaitP95:   w[1],
+		WaitP99:   w[2],
+	}
+}
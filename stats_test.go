@@ -0,0 +1,65 @@
+package tinyPool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	p, _ := NewPool(1, WithQueueCap(2), WithNonblocking(true))
+	defer p.Close()
+	p.Tune(1)
+
+	release := saturate(p)
+	_ = p.Submit(func() {})
+	_ = p.Submit(func() {})
+	if err := p.Submit(func() {}); err != ErrQueueFull {
+		t.Fatalf("err = %v, want ErrQueueFull", err)
+	}
+
+	s := p.Stats()
+	if s.Cap != 1 || s.Running != 1 || s.Idle != 0 || s.Free != 0 || s.Waiting != 2 || s.Rejected != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+
+	release()
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := p.Stats(); s.Submitted != 3 || s.Completed != 3 || s.Waiting != 0 {
+		t.Fatalf("stats after shutdown = %+v, want 3 submitted and completed", s)
+	}
+}
+
+func TestStatsQueueWait(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+	p.Tune(1)
+
+	release := saturate(p)
+	_ = p.Submit(func() {})
+	time.Sleep(20 * time.Millisecond)
+	release()
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := p.Stats(); s.WaitP99 < 15*time.Millisecond {
+		t.Fatalf("wait p99 = %v, want about 20ms", s.WaitP99)
+	}
+}
+
+func TestPoolWithFuncQueueWait(t *testing.T) {
+	p, _ := NewPoolWithFunc(1, func(d time.Duration) { time.Sleep(d) })
+	p.Tune(1)
+	_ = p.Invoke(20 * time.Millisecond)
+	_ = p.Invoke(0)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := p.Stats(); s.WaitP99 < 15*time.Millisecond {
+		t.Fatalf("wait p99 = %v, want about 20ms", s.WaitP99)
+	}
+}
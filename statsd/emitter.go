@@ -0,0 +1,114 @@
+// Package statsd pushes the stats of a tinyPool pool to a StatsD server.
+package statsd
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pandaknight2021/tinyPool"
+)
+
+// Source is a pool whose stats are pushed, both tinyPool.Pool and
+// tinyPool.PoolWithFunc implement it.
+type Source interface {
+	Stats() tinyPool.Stats
+	Durations() tinyPool.DurationHistogram
+}
+
+// Emitter sends the stats of a pool to StatsD over UDP at a fixed
+// interval: the worker and queue gauges, the task counters since the last
+// push, and the mean task duration over the interval as a timing.
+type Emitter struct {
+	p      Source
+	conn   net.Conn
+	prefix string
+
+	// last holds the counters of the previous push
+	last     tinyPool.Stats
+	lastHist tinyPool.DurationHistogram
+
+	quit chan struct{}
+	once sync.Once
+	done chan struct{}
+}
+
+// Start starts pushing the stats of p to the StatsD server at addr every
+// interval. Metric names start with prefix, e.g. "myapp.pool.".
+func Start(p Source, addr, prefix string, interval time.Duration) (*Emitter, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("statsd: non-positive interval %v", interval)
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Emitter{
+		p:        p,
+		conn:     conn,
+		prefix:   prefix,
+		last:     p.Stats(),
+		lastHist: p.Durations(),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go e.run(interval)
+	return e, nil
+}
+
+// Close pushes the stats one last time and stops the emitter.
+func (e *Emitter) Close() error {
+	e.once.Do(func() {
+		close(e.quit)
+	})
+	<-e.done
+	return e.conn.Close()
+}
+
+func (e *Emitter) run(interval time.Duration) {
+	defer close(e.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.push()
+		case <-e.quit:
+			e.push()
+			return
+		}
+	}
+}
+
+// push sends one packet with all metrics, errors are dropped like StatsD
+// clients usually do.
+func (e *Emitter) push() {
+	s, h := e.p.Stats(), e.p.Durations()
+
+	var b bytes.Buffer
+	gauge := func(name string, v int) {
+		fmt.Fprintf(&b, "%s%s:%d|g\n", e.prefix, name, v)
+	}
+	count := func(name string, v int64) {
+		fmt.Fprintf(&b, "%s%s:%d|c\n", e.prefix, name, v)
+	}
+
+	gauge("capacity", s.Cap)
+	gauge("workers.running", s.Running)
+	gauge("workers.idle", s.Idle)
+	gauge("queue.depth", s.Waiting)
+	count("tasks.submitted", s.Submitted-e.last.Submitted)
+	count("tasks.completed", s.Completed-e.last.Completed)
+	count("tasks.rejected", s.Rejected-e.last.Rejected)
+	if n := h.Count - e.lastHist.Count; n > 0 {
+		mean := (h.Sum - e.lastHist.Sum) / time.Duration(n)
+		fmt.Fprintf(&b, "%stask.duration:%g|ms\n", e.prefix, float64(mean)/float64(time.Millisecond))
+	}
+	e.last, e.lastHist = s, h
+
+	_, _ = e.conn.Write(bytes.TrimSuffix(b.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,46 @@
+package statsd
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pandaknight2021/tinyPool"
+)
+
+func TestEmitter(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	p, _ := tinyPool.NewPool(4)
+	e, err := Start(p, server.LocalAddr().String(), "app.pool.", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		_ = p.Submit(func() { time.Sleep(time.Millisecond) })
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_ = e.Close()
+
+	buf := make([]byte, 1024)
+	_ = server.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := server.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet := string(buf[:n])
+	for _, line := range []string{"app.pool.tasks.completed:3|c", "app.pool.queue.depth:0|g", "app.pool.task.duration:"} {
+		if !strings.Contains(packet, line) {
+			t.Fatalf("packet %q lacks %q", packet, line)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package tinyPool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// finishedStatuses is the number of finished tasks whose status is kept
+// for Status.
+const finishedStatuses = 1024
+
+// TaskID identifies a task submitted by SubmitTracked.
+type TaskID uint64
+
+// TaskState is the stage a tracked task is in.
+type TaskState int
+
+const (
+	// TaskQueued tasks wait for a worker, also between retries.
+	TaskQueued TaskState = iota
+	TaskRunning
+	TaskDone
+	// TaskFailed tasks returned an error on their last attempt, or
+	// panicked.
+	TaskFailed
+	// TaskCancelled tasks were dropped before they ran, such as tasks
+	// that expired or couldn't be submitted, or returned the error of
+	// their canceled context.
+	TaskCancelled
+	// TaskTimedOut tasks ran for longer than the timeout given to
+	// SubmitWithTimeout.
+	TaskTimedOut
+)
+
+func (s TaskState) String() string {
+	switch s {
+	case TaskQueued:
+		return "queued"
+	case TaskRunning:
+		return "running"
+	case TaskDone:
+		return "done"
+	case TaskFailed:
+		return "failed"
+	case TaskCancelled:
+		return "cancelled"
+	case TaskTimedOut:
+		return "timed out"
+	}
+	return fmt.Sprintf("TaskState(%d)", int(s))
+}
+
+// finished reports whether s is final.
+func (s TaskState) finished() bool {
+	return s >= TaskDone
+}
+
+// TaskStatus is the state of a tracked task and when it got there.
+type TaskStatus struct {
+	ID    TaskID
+	Name  string
+	State TaskState
+
+	// Submitted is when the task was submitted, Started when its last
+	// attempt started and Finished when it was done, failed or cancelled.
+	Submitted time.Time
+	Started   time.Time
+	Finished  time.Time
+
+	// Err is the error the task failed or was cancelled with.
+	Err error
+
+	// LastHeartbeat is the latest beat of a task submitted by
+	// SubmitWithHeartbeat, Stalled tells that it missed its beats.
+	LastHeartbeat time.Time
+	Stalled       bool
+
+	// Progress is the latest fraction done reported by a task submitted
+	// by SubmitWithProgress.
+	Progress float64
+}
+
+// MarshalJSON encodes s with Err as its message, as errors mostly have no
+// exported fields to encode.
+func (s TaskStatus) MarshalJSON() ([]byte, error) {
+	type status TaskStatus
+	v := struct {
+		status
+		Err string `json:",omitempty"`
+	}{status: status(s)}
+	if s.Err != nil {
+		v.Err = s.Err.Error()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes s encoded by MarshalJSON, Err as an error with the
+// encoded message.
+func (s *TaskStatus) UnmarshalJSON(data []byte) error {
+	type status TaskStatus
+	v := struct {
+		*status
+		Err string
+	}{status: (*status)(s)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	s.Err = nil
+	if v.Err != "" {
+		s.Err = errors.New(v.Err)
+	}
+	return nil
+}
+
+// TaskHandle tracks a task submitted by SubmitTracked.
+type TaskHandle struct {
+	mu     sync.Mutex
+	status TaskStatus
+
+	// done is closed when the task has finished
+	done chan struct{}
+
+	// ctx is the context of a task submitted by SubmitCancelable, cancel
+	// cancels it
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// hb is the heartbeat of a task submitted by SubmitWithHeartbeat,
+	// progress the progress of one submitted by SubmitWithProgress
+	hb       *Heartbeat
+	progress *Progress
+
+	p *Pool
+}
+
+// ID returns the ID of the task, which Pool.Status accepts too.
+func (h *TaskHandle) ID() TaskID {
+	return h.status.ID
+}
+
+// Status returns the current status of the task.
+func (h *TaskHandle) Status() TaskStatus {
+	h.mu.Lock()
+	status := h.status
+	h.mu.Unlock()
+
+	if h.hb != nil && !status.Started.IsZero() {
+		status.LastHeartbeat = h.hb.Last()
+	}
+	if h.progress != nil {
+		status.Progress = h.progress.Value()
+	}
+	return status
+}
+
+// set moves the task to state unless it has finished already.
+func (h *TaskHandle) set(state TaskState, err error) {
+	h.mu.Lock()
+	if h.status.State.finished() {
+		h.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	h.status.State = state
+	switch {
+	case state == TaskRunning:
+		h.status.Started = now
+	case state.finished():
+		h.status.Finished = now
+		h.status.Err = err
+	}
+	h.mu.Unlock()
+
+	if state.finished() {
+		h.settle()
+	}
+}
+
+// settle releases the finished task.
+func (h *TaskHandle) settle() {
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.p.statuses.finish(h.status.ID)
+	close(h.done)
+}
+
+// Wait blocks until the task has finished and returns the error it failed
+// or was cancelled with, or ctx.Err() if ctx is done first.
+func (h *TaskHandle) Wait(ctx context.Context) error {
+	select {
+	case <-h.done:
+		return h.Status().Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// taskStatuses holds the handles of the tracked tasks which are still
+// going and of the latest finished ones.
+type taskStatuses struct {
+	mu      sync.Mutex
+	seq     TaskID
+	handles map[TaskID]*TaskHandle
+
+	// finished holds the IDs of the latest finished tasks in a ring
+	finished [finishedStatuses]TaskID
+	next     int
+}
+
+// add registers h under a new ID.
+func (s *taskStatuses) add(h *TaskHandle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.handles == nil {
+		s.handles = make(map[TaskID]*TaskHandle)
+	}
+	s.seq++
+	h.status.ID = s.seq
+	s.handles[s.seq] = h
+}
+
+// finish forgets the oldest finished task to make room for id.
+func (s *taskStatuses) finish(id TaskID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.handles, s.finished[s.next])
+	s.finished[s.next] = id
+	s.next = (s.next + 1) % finishedStatuses
+}
+
+// SubmitTracked submits a task and returns a handle telling its status.
+// The status of the task can be looked up by its ID with Status as well.
+// If the task cannot be submitted, it is cancelled with the error, which
+// is returned too.
+func (p *Pool) SubmitTracked(task func() error, opts ...TaskOption) (*TaskHandle, error) {
+	return p.submitTracked(&TaskHandle{done: make(chan struct{}), p: p}, task, opts)
+}
+
+// submitTracked submits task tracked by h.
+func (p *Pool) submitTracked(h *TaskHandle, task func() error, opts []TaskOption) (*TaskHandle, error) {
+	j := newJob(opts)
+	h.status = TaskStatus{Name: j.name, State: TaskQueued, Submitted: time.Now()}
+	p.statuses.add(h)
+
+	var runs int
+	var lastErr error
+	j.fnErr = func() error {
+		h.set(TaskRunning, nil)
+		defer func() {
+			if r := recover(); r != nil {
+				h.set(TaskFailed, fmt.Errorf("task panicked: %v", r))
+				panic(r)
+			}
+		}()
+
+		lastErr = task()
+		if runs++; lastErr != nil && runs < j.maxAttempts {
+			// retried later
+			h.set(TaskQueued, nil)
+		}
+		return lastErr
+	}
+	j.finish = func() {
+		if errors.Is(lastErr, ErrTaskTimeout) {
+			h.set(TaskTimedOut, lastErr)
+		} else if h.ctx != nil && h.ctx.Err() != nil && errors.Is(lastErr, h.ctx.Err()) {
+			h.set(TaskCancelled, lastErr)
+		} else if lastErr != nil {
+			h.set(TaskFailed, lastErr)
+		} else {
+			h.set(TaskDone, nil)
+		}
+	}
+	j.abort = func(err error) {
+		h.set(TaskCancelled, err)
+	}
+
+	if err := p.submit(j); err != nil {
+		h.set(TaskCancelled, err)
+		return h, err
+	}
+	return h, nil
+}
+
+// Status returns the status of the tracked task id. It reports false for
+// unknown IDs, and for tasks which finished before the latest 1024 ones.
+func (p *Pool) Status(id TaskID) (TaskStatus, bool) {
+	p.statuses.mu.Lock()
+	h := p.statuses.handles[id]
+	p.statuses.mu.Unlock()
+
+	if h == nil {
+		return TaskStatus{}, false
+	}
+	return h.Status(), true
+}
@@ -0,0 +1,136 @@
+package tinyPool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubmitTracked(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+	p.Tune(1)
+
+	release := make(chan struct{})
+	running, _ := p.SubmitTracked(func() error { <-release; return nil }, WithTaskName("export"))
+	failing, _ := p.SubmitTracked(func() error { return errors.New("boom") })
+	expired, _ := p.SubmitTracked(func() error { return nil }, WithDeadline(time.Now()))
+	time.Sleep(20 * time.Millisecond)
+
+	if s, ok := p.Status(running.ID()); !ok || s.State != TaskRunning || s.Name != "export" || s.Started.IsZero() {
+		t.Fatalf("status = %+v, %v", s, ok)
+	}
+	if s := failing.Status(); s.State != TaskQueued || !s.Started.IsZero() {
+		t.Fatalf("status = %+v, want queued", s)
+	}
+
+	close(release)
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := running.Status(); s.State != TaskDone || s.Finished.Before(s.Started) {
+		t.Fatalf("status = %+v, want done", s)
+	}
+	if s := failing.Status(); s.State != TaskFailed || s.Err == nil || s.Err.Error() != "boom" {
+		t.Fatalf("status = %+v, want failed", s)
+	}
+	if s := expired.Status(); s.State != TaskCancelled || s.Err != ErrTaskExpired {
+		t.Fatalf("status = %+v, want cancelled", s)
+	}
+	if _, ok := p.Status(0); ok {
+		t.Fatal("status of unknown task")
+	}
+}
+
+func TestTrackedTaskRetries(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	runs := 0
+	h, _ := p.SubmitTracked(func() error {
+		if runs++; runs < 2 {
+			return errors.New("flaky")
+		}
+		return nil
+	}, WithRetry(3, 30*time.Millisecond))
+
+	time.Sleep(10 * time.Millisecond)
+	if s := h.Status(); s.State != TaskQueued || s.Started.IsZero() {
+		t.Fatalf("status = %+v, want queued for retry", s)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if s := h.Status(); s.State != TaskDone || s.Err != nil {
+		t.Fatalf("status = %+v, want done", s)
+	}
+}
+
+func TestStatusRetention(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+
+	first, _ := p.SubmitTracked(func() error { return nil })
+	// the oldest task has to finish first to be the one forgotten
+	for deadline := time.Now().Add(time.Second); first.Status().State != TaskDone; {
+		if time.Now().After(deadline) {
+			t.Fatal("first task didn't finish")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < finishedStatuses; i++ {
+		_, _ = p.SubmitTracked(func() error { return nil })
+	}
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.Status(first.ID()); ok {
+		t.Fatal("status of the oldest finished task kept")
+	}
+	if s := first.Status(); s.State != TaskDone {
+		t.Fatalf("status = %+v, want done on the handle", s)
+	}
+}
+
+func TestTaskHandleWait(t *testing.T) {
+	p, _ := NewPool(2)
+	defer p.Close()
+
+	release := make(chan struct{})
+	slow, _ := p.SubmitTracked(func() error { <-release; return nil })
+	failing, _ := p.SubmitTracked(func() error { return errors.New("boom") })
+
+	if err := failing.Wait(context.Background()); err == nil || err.Error() != "boom" {
+		t.Fatalf("Wait() = %v, want boom", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := slow.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Wait() = %v, want deadline exceeded", err)
+	}
+	close(release)
+	if err := slow.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+}
+
+func TestTaskStatusJSON(t *testing.T) {
+	s := TaskStatus{ID: 7, Name: "export", State: TaskFailed, Err: errors.New("boom")}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"Err":"boom"`) {
+		t.Fatalf("encoded %s, want the message of Err", data)
+	}
+
+	var got TaskStatus
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 7 || got.Name != "export" || got.State != TaskFailed || got.Err == nil || got.Err.Error() != "boom" {
+		t.Fatalf("decoded %+v", got)
+	}
+}
@@ -0,0 +1,62 @@
+package tinyPool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stuck returns the number of workers whose task has been running for
+// longer than the limit set by WithStuckWorkerLimit.
+func (p *engine[T]) Stuck() int32 {
+	return atomic.LoadInt32(&p.stuck)
+}
+
+// limit returns the number of workers which may be started, the capacity
+// plus the replacements of stuck workers and the extra workers of a burst.
+func (p *engine[T]) limit() int32 {
+	limit := p.Cap()
+	if p.options.ReplaceStuckWorkers {
+		limit += atomic.LoadInt32(&p.stuck)
+	}
+	if p.options.BurstExtra > 0 && p.bursting() {
+		limit += int32(p.options.BurstExtra)
+	}
+	return limit
+}
+
+// watchStuck flags the worker running item as stuck once item has run for
+// the stuck worker limit. The returned function stops watching it and
+// clears the flag.
+func (p *engine[T]) watchStuck(item T, start time.Time) func() {
+	d := p.options.StuckWorkerLimit
+	if d <= 0 {
+		return unwatchedStuck
+	}
+	return p.stuckTimer(item, start, d)
+}
+
+// unwatchedStuck stops watching a task without stuck worker limit.
+func unwatchedStuck() {}
+
+// stuckTimer flags the worker running item as stuck once item has run for
+// d. Split from watchStuck, so item only escapes with a limit.
+func (p *engine[T]) stuckTimer(item T, start time.Time, d time.Duration) func() {
+	// 0 while running, 1 once stuck, 2 once finished in time
+	var state int32
+	t := time.AfterFunc(d, func() {
+		if !atomic.CompareAndSwapInt32(&state, 0, 1) {
+			return
+		}
+		atomic.AddInt32(&p.stuck, 1)
+		p.log(LevelWarn, "worker stuck", "task", p.name(item), "elapsed", time.Since(start))
+		if p.options.ReplaceStuckWorkers {
+			p.tryStartWorker()
+		}
+	})
+	return func() {
+		t.Stop()
+		if !atomic.CompareAndSwapInt32(&state, 0, 2) {
+			atomic.AddInt32(&p.stuck, -1)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package tinyPool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithStuckWorkerLimit(t *testing.T) {
+	p, _ := NewPool(1, WithStuckWorkerLimit(20*time.Millisecond, true))
+	defer p.Close()
+	p.Tune(1)
+
+	release := make(chan struct{})
+	_ = p.Submit(func() { <-release })
+
+	ran := make(chan struct{})
+	_ = p.Submit(func() { close(ran) })
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("stuck worker was not replaced")
+	}
+	if n := p.Stats().Stuck; n != 1 {
+		t.Fatalf("stuck = %d, want 1", n)
+	}
+
+	close(release)
+	time.Sleep(50 * time.Millisecond)
+	if n := p.Stuck(); n != 0 {
+		t.Fatalf("stuck = %d after the task returned, want 0", n)
+	}
+	if n := p.Running(); n != 1 {
+		t.Fatalf("running = %d, want the replacement to retire", n)
+	}
+}
+
+func TestStuckWorkerNotReplaced(t *testing.T) {
+	p, _ := NewPool(1, WithStuckWorkerLimit(20*time.Millisecond, false))
+	defer p.Close()
+	p.Tune(1)
+
+	release := make(chan struct{})
+	defer close(release)
+	_ = p.Submit(func() { <-release })
+	ran := make(chan struct{})
+	_ = p.Submit(func() { close(ran) })
+
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case <-ran:
+		t.Fatal("task ran beside the stuck one")
+	default:
+	}
+	if n := p.Stuck(); n != 1 {
+		t.Fatalf("stuck = %d, want 1", n)
+	}
+	if n := p.Running(); n != 1 {
+		t.Fatalf("running = %d, want 1", n)
+	}
+}
@@ -0,0 +1,198 @@
+package tinyPool
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// tagLimits tracks the running tasks of the tags in Options.TagLimits.
+type tagLimits struct {
+	mu   sync.Mutex
+	tags map[string]*tagSlots
+}
+
+// tagSlots holds the running count and the waiting tasks of a tag.
+type tagSlots struct {
+	limit   int
+	running int
+	waiting []job
+}
+
+// WithTag sets the tag of the task. Tags limited by WithTagLimits run at
+// most as many tasks at once as their limit allows, the tasks over it wait
+// without taking a worker, though they take a slot of the bounded queue.
+func WithTag(tag string) TaskOption {
+	return func(j *job) {
+		j.tag = tag
+	}
+}
+
+func (t *tagLimits) init(limits map[string]int) {
+	t.tags = make(map[string]*tagSlots, len(limits))
+	for tag, n := range limits {
+		if n < 1 {
+			n = 1
+		}
+		t.tags[tag] = &tagSlots{limit: n}
+	}
+}
+
+// limited reports whether tag has a concurrency limit. The set of limited
+// tags never changes, so no lock is needed.
+func (t *tagLimits) limited(tag string) bool {
+	return tag != "" && t.tags[tag] != nil
+}
+
+// submit submits j, or parks it if its tag is at its limit.
+func (p *Pool) submit(j job) error {
+	j.submitted = time.Now()
+	j.trace = newTaskTrace(j.name)
+	if err := p.breakers.allow(j.tag); err != nil {
+		p.reject(j, err)
+		return err
+	}
+	if !p.tags.limited(j.tag) {
+		return p.engine.submit(j)
+	}
+	if p.IsClosed() {
+		j.trace.end(ErrPoolClosed)
+		return ErrPoolClosed
+	}
+	parked, err := p.park(j)
+	if err != nil {
+		p.reject(j, err)
+		p.queueFull(j, err)
+		return err
+	}
+	if parked {
+		return nil
+	}
+
+	err = p.engine.submit(j)
+	if err != nil {
+		p.releaseTag(j.tag)
+	}
+	return err
+}
+
+// trySubmit is like submit but never blocks.
+func (p *Pool) trySubmit(j job) bool {
+	j.submitted = time.Now()
+	j.trace = newTaskTrace(j.name)
+	if err := p.breakers.allow(j.tag); err != nil {
+		p.reject(j, err)
+		return false
+	}
+	if !p.tags.limited(j.tag) {
+		return p.engine.trySubmit(j)
+	}
+	if p.IsClosed() {
+		j.trace.end(ErrPoolClosed)
+		return false
+	}
+	parked, err := p.park(j)
+	if err != nil {
+		p.reject(j, err)
+		p.queueFull(j, err)
+		return false
+	}
+	if parked {
+		return true
+	}
+
+	if !p.engine.trySubmit(j) {
+		p.releaseTag(j.tag)
+		return false
+	}
+	return true
+}
+
+// park queues j behind the running tasks of its tag if the tag is at its
+// limit. Otherwise it takes a slot of the tag and returns false. Parked
+// tasks take queue slots, it fails with ErrQueueFull if the bounded queue
+// has none left.
+func (p *Pool) park(j job) (bool, error) {
+	s := p.tags.tags[j.tag]
+
+	p.tags.mu.Lock()
+	defer p.tags.mu.Unlock()
+	if s.running < s.limit {
+		s.running++
+		return false, nil
+	}
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			return false, ErrQueueFull
+		}
+	}
+	// parked tasks are pending, so Shutdown waits for them
+	atomic.AddInt64(&p.pending, 1)
+	p.accept(j)
+	s.waiting = append(s.waiting, j)
+	return true, nil
+}
+
+// unpark gives back the queue slot of a task taken off the parked ones.
+func (p *Pool) unpark() {
+	if p.slots != nil {
+		<-p.slots
+	}
+}
+
+// takeParked removes all parked tasks and returns them.
+func (t *tagLimits) takeParked() []job {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var parked []job
+	for _, s := range t.tags {
+		parked = append(parked, s.waiting...)
+		s.waiting = nil
+	}
+	return parked
+}
+
+// releaseTag gives up the slot of tag taken by a task which finished or
+// failed to submit. The slot passes to the first parked task, which is
+// queued again, even while the pool shuts down.
+func (p *Pool) releaseTag(tag string) {
+	s := p.tags.tags[tag]
+	for {
+		p.tags.mu.Lock()
+		if len(s.waiting) == 0 {
+			s.running--
+			p.tags.mu.Unlock()
+			return
+		}
+		next := s.waiting[0]
+		s.waiting[0] = job{}
+		s.waiting = s.waiting[1:]
+		p.tags.mu.Unlock()
+		p.unpark()
+
+		select {
+		case <-p.quitSig:
+			// the pool quit, drop the parked task
+			atomic.AddInt64(&p.pending, -1)
+			if next.abort != nil {
+				next.abort(ErrPoolClosed)
+			}
+			continue
+		default:
+		}
+
+		err := p.place(next)
+		// place counts the task again, if it succeeded
+		atomic.AddInt64(&p.pending, -1)
+		atomic.AddInt64(&p.submitted, -1)
+		if err == nil {
+			return
+		}
+		if next.abort != nil {
+			next.abort(err)
+		}
+	}
+}
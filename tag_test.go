@@ -0,0 +1,117 @@
+package tinyPool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithTagLimits(t *testing.T) {
+	p, _ := NewPool(8, WithTagLimits(map[string]int{"db": 2}))
+	defer p.Close()
+
+	var db, peak, other int32
+	var wg sync.WaitGroup
+	wg.Add(40)
+	for i := 0; i < 20; i++ {
+		_ = p.Submit(func() {
+			defer wg.Done()
+			n := atomic.AddInt32(&db, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&db, -1)
+		}, WithTag("db"))
+		_ = p.Submit(func() {
+			defer wg.Done()
+			atomic.AddInt32(&other, 1)
+		}, WithTag("s3"))
+	}
+	wg.Wait()
+
+	if peak != 2 {
+		t.Fatalf("peak concurrency of tag = %d, want 2", peak)
+	}
+	if other != 20 {
+		t.Fatalf("ran %d untagged tasks, want 20", other)
+	}
+}
+
+func TestTagShutdown(t *testing.T) {
+	p, _ := NewPool(4, WithTagLimits(map[string]int{"db": 1}))
+
+	var n int32
+	for i := 0; i < 10; i++ {
+		_ = p.Submit(func() {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&n, 1)
+		}, WithTag("db"))
+	}
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 {
+		t.Fatalf("ran %d parked tasks before shutdown, want 10", n)
+	}
+}
+
+func TestTagParkedQueueCap(t *testing.T) {
+	p, _ := NewPool(4, WithTagLimits(map[string]int{"db": 1}), WithQueueCap(2))
+	defer p.Close()
+
+	block := make(chan struct{})
+	var ran int32
+	for i := 0; i < 3; i++ {
+		if err := p.Submit(func() {
+			<-block
+			atomic.AddInt32(&ran, 1)
+		}, WithTag("db")); err != nil {
+			t.Fatalf("submit %d failed: %v", i, err)
+		}
+	}
+	if err := p.Submit(func() {}, WithTag("db")); err != ErrQueueFull {
+		t.Fatalf("submit past the queue cap = %v, want ErrQueueFull", err)
+	}
+	if n := p.Stats().Rejected; n != 1 {
+		t.Fatalf("%d rejected tasks, want 1", n)
+	}
+
+	close(block)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran != 3 {
+		t.Fatalf("ran %d of the 3 accepted tasks", ran)
+	}
+}
+
+func TestTagConcurrencyLimit(t *testing.T) {
+	p, _ := NewPool(4, WithTagLimits(map[string]int{"db": 1}),
+		WithConcurrencyLimit(NewAIMDLimit(1, 1, 1, time.Second), false))
+	defer p.Close()
+
+	var n int32
+	for i := 0; i < 3; i++ {
+		_ = p.Submit(func() {
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&n, 1)
+		}, WithTag("db"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.Wait(ctx); err != nil {
+		t.Fatalf("Wait() = %v with %d of 3 tagged tasks run", err, atomic.LoadInt32(&n))
+	}
+	// each task is measured on its own, not with the parked ones after it
+	if sum := p.Durations().Sum; sum > 90*time.Millisecond {
+		t.Fatalf("tasks took %v in total, want about 60ms", sum)
+	}
+}
@@ -0,0 +1,131 @@
+package tinyPool
+
+import (
+	"context"
+	"time"
+)
+
+// Task is a task submitted by SubmitTask. Submitting a pointer to a
+// reused struct implementing it, instead of a func, saves allocating a
+// closure per task.
+type Task interface {
+	Run()
+}
+
+// job is a task queued by Pool together with its settings.
+type job struct {
+	fn func()
+
+	// task is set instead of fn for tasks submitted by SubmitTask
+	task Task
+
+	// fnErr is set instead of fn for tasks which report an error
+	fnErr func() error
+
+	// fnState is set instead of fn for tasks using the state of the
+	// worker, which is set by the worker before it runs the task
+	fnState func(WorkerState)
+	worker  *WorkerState
+
+	name string
+
+	// producer is the handle the task was submitted through, if any
+	producer *Producer
+
+	// tag is the class of the task, limited by Options.TagLimits
+	tag string
+
+	priority Priority
+
+	// submitted is when the task was last submitted, trace its trace
+	// task since then
+	submitted time.Time
+	trace     *taskTrace
+
+	// deadline is the latest time the task may start, zero for no deadline
+	deadline time.Time
+
+	// ctx drops the task if it is done before the task starts
+	ctx context.Context
+
+	// maxAttempts is the number of runs of a failing task, attempt counts
+	// the runs so far
+	maxAttempts int
+	attempt     int
+	backoff     time.Duration
+
+	// abort is called instead of the task when it is dropped
+	abort func(err error)
+
+	// finish is called after the last run of the task, unless it panicked
+	finish func()
+
+	// intercept is called on the worker instead of the task
+	intercept func(run func() error) error
+}
+
+// call runs the task and returns its error, if it reports one.
+func (j *job) call() error {
+	if j.fnErr != nil {
+		return j.fnErr()
+	}
+	if j.fnState != nil {
+		var ws WorkerState
+		if j.worker != nil {
+			ws = *j.worker
+		}
+		j.fnState(ws)
+		return nil
+	}
+	if j.task != nil {
+		j.task.Run()
+		return nil
+	}
+	j.fn()
+	return nil
+}
+
+// TaskOption configures a single submitted task.
+type TaskOption func(j *job)
+
+// WithTaskName names the task, the name is reported in its TaskEvent.
+func WithTaskName(name string) TaskOption {
+	return func(j *job) {
+		j.name = name
+	}
+}
+
+// WithInterceptor wraps the task: intercept is called on the worker
+// instead of it, and must call run to run the task. It returns the error
+// of the task, or one of its own. Interceptors are meant for tracing and
+// logging around a task.
+func WithInterceptor(intercept func(run func() error) error) TaskOption {
+	return func(j *job) {
+		if outer := j.intercept; outer != nil {
+			j.intercept = func(run func() error) error {
+				return outer(func() error {
+					return intercept(run)
+				})
+			}
+			return
+		}
+		j.intercept = intercept
+	}
+}
+
+func newJob(opts []TaskOption) job {
+	if len(opts) == 0 {
+		return job{}
+	}
+	return newJobWith(opts)
+}
+
+// newJobWith is newJob for a task with options. Split from newJob, so only
+// such jobs escape to the heap.
+func newJobWith(opts []TaskOption) job {
+	var j job
+	for _, opt := range opts {
+		opt(&j)
+	}
+	return j
+}
@@ -0,0 +1,76 @@
+package tinyPool
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWithInterceptor(t *testing.T) {
+	errs := make(chan error, 1)
+	p, _ := NewPool(1, WithErrorHandler(func(err error) {
+		errs <- err
+	}))
+	defer p.Close()
+
+	var order []string
+	trace := func(name string) TaskOption {
+		return WithInterceptor(func(run func() error) error {
+			order = append(order, name)
+			err := run()
+			order = append(order, name)
+			return err
+		})
+	}
+
+	errBad := errors.New("bad")
+	_ = p.SubmitErr(func() error {
+		order = append(order, "task")
+		return errBad
+	}, trace("outer"), trace("inner"))
+
+	select {
+	case err := <-errs:
+		if err != errBad {
+			t.Fatalf("reported %v, want %v", err, errBad)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("error of intercepted task not reported")
+	}
+	if want := "outer inner task inner outer"; fmt.Sprint(order) != "["+want+"]" {
+		t.Fatalf("order = %v, want %s", order, want)
+	}
+}
+
+type countTask struct {
+	runs chan string
+	name string
+}
+
+func (t *countTask) Run() {
+	t.runs <- t.name
+}
+
+func TestSubmitTask(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Close()
+
+	task := &countTask{runs: make(chan string, 2), name: "a"}
+	_ = p.SubmitTask(task)
+	_ = p.SubmitTask(task, WithTaskName("named"))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case name := <-task.runs:
+			if name != "a" {
+				t.Fatalf("ran %q, want a", name)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%d of 2 tasks ran", i)
+		}
+	}
+	if err := p.SubmitTask(nil); err != nil {
+		t.Fatalf("SubmitTask(nil) = %v, want nil", err)
+	}
+}
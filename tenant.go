@@ -0,0 +1,186 @@
+package tinyPool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Tenant submits tasks to a pool within its own quota: at most
+// maxConcurrent of its tasks run at once and at most maxQueued more wait
+// for their turn.
+type Tenant struct {
+	p    *Pool
+	name string
+
+	mu            sync.Mutex
+	maxConcurrent int
+	maxQueued     int
+	running       int
+	waiting       []keyedTask
+	completed     uint64
+	rejected      uint64
+}
+
+// TenantStats is a snapshot of the usage of a tenant.
+type TenantStats struct {
+	Name string
+
+	// Running is the number of submitted tasks which have not finished.
+	Running int
+
+	// Queued is the number of tasks waiting for the quota of the tenant.
+	Queued int
+
+	// Completed is the number of finished tasks.
+	Completed uint64
+
+	// Rejected is the number of tasks refused because the tenant queue
+	// was full or the pool closed.
+	Rejected uint64
+}
+
+// Tenant returns the tenant named name, creating it on the first call.
+// Later calls update its quota. A maxConcurrent of 0 or less means no
+// limit, and so does a maxQueued of 0 or less.
+func (p *Pool) Tenant(name string, maxConcurrent, maxQueued int) *Tenant {
+	p.tenantsMu.Lock()
+	defer p.tenantsMu.Unlock()
+
+	if p.tenants == nil {
+		p.tenants = make(map[string]*Tenant)
+	}
+	t := p.tenants[name]
+	if t == nil {
+		t = &Tenant{p: p, name: name}
+		p.tenants[name] = t
+	}
+
+	t.mu.Lock()
+	t.maxConcurrent = maxConcurrent
+	t.maxQueued = maxQueued
+	t.mu.Unlock()
+	return t
+}
+
+// TenantStats returns the stats of all tenants of the pool.
+func (p *Pool) TenantStats() []TenantStats {
+	p.tenantsMu.Lock()
+	defer p.tenantsMu.Unlock()
+
+	stats := make([]TenantStats, 0, len(p.tenants))
+	for _, t := range p.tenants {
+		stats = append(stats, t.Stats())
+	}
+	return stats
+}
+
+// Name returns the name of the tenant.
+func (t *Tenant) Name() string {
+	return t.name
+}
+
+// Submit submits a task on behalf of the tenant. If the tenant runs as
+// many tasks as it may, the task waits in the tenant queue. It returns
+// ErrQueueFull if that queue is full too.
+func (t *Tenant) Submit(task func(), opts ...TaskOption) error {
+	if task == nil {
+		return nil
+	}
+	if t.p.IsClosed() {
+		return ErrPoolClosed
+	}
+
+	kt := keyedTask{fn: task, opts: opts}
+	t.mu.Lock()
+	if t.maxConcurrent > 0 && t.running >= t.maxConcurrent {
+		if t.maxQueued > 0 && len(t.waiting) >= t.maxQueued {
+			t.rejected++
+			t.mu.Unlock()
+			return ErrQueueFull
+		}
+		// waiting tasks are pending, so Shutdown waits for them
+		atomic.AddInt64(&t.p.pending, 1)
+		t.waiting = append(t.waiting, kt)
+		t.mu.Unlock()
+		return nil
+	}
+	t.running++
+	t.mu.Unlock()
+
+	err := t.submit(kt, false)
+	if err != nil {
+		t.mu.Lock()
+		t.rejected++
+		t.mu.Unlock()
+		t.next(false)
+	}
+	return err
+}
+
+// submit submits kt, which holds a slot of the tenant quota. The slot is
+// given up when kt finishes or is dropped. A task which waited in the
+// tenant queue was accepted already, so it is still queued while the pool
+// shuts down.
+func (t *Tenant) submit(kt keyedTask, waited bool) error {
+	j := newJob(kt.opts)
+	j.fn = func() {
+		defer t.next(true)
+		kt.fn()
+	}
+	abort := j.abort
+	j.abort = func(err error) {
+		if abort != nil {
+			abort(err)
+		}
+		t.next(false)
+	}
+	if waited && t.p.IsClosed() {
+		return t.p.resubmit(j)
+	}
+	return t.p.submit(j)
+}
+
+// next gives up the slot of a task of the tenant, which completed if it
+// ran, and submits the next waiting tasks the quota allows.
+func (t *Tenant) next(completed bool) {
+	t.mu.Lock()
+	t.running--
+	if completed {
+		t.completed++
+	}
+	for len(t.waiting) > 0 && (t.maxConcurrent <= 0 || t.running < t.maxConcurrent) {
+		kt := t.waiting[0]
+		t.waiting[0] = keyedTask{}
+		t.waiting = t.waiting[1:]
+		t.running++
+		t.mu.Unlock()
+
+		err := t.submit(kt, true)
+		// the task is counted again by the submission, if it succeeded
+		atomic.AddInt64(&t.p.pending, -1)
+
+		t.mu.Lock()
+		if err != nil {
+			// the pool is closed, drop the rest of the queue
+			t.running--
+			t.rejected += uint64(len(t.waiting)) + 1
+			atomic.AddInt64(&t.p.pending, -int64(len(t.waiting)))
+			t.waiting = nil
+		}
+	}
+	t.mu.Unlock()
+}
+
+// Stats returns the current usage of the tenant.
+func (t *Tenant) Stats() TenantStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return TenantStats{
+		Name:      t.name,
+		Running:   t.running,
+		Queued:    len(t.waiting),
+		Completed: t.completed,
+		Rejected:  t.rejected,
+	}
+}
@@ -0,0 +1,100 @@
+package tinyPool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTenant(t *testing.T) {
+	p, _ := NewPool(8)
+	defer p.Close()
+	billing := p.Tenant("billing", 2, 3)
+
+	var running, peak int32
+	block := make(chan struct{})
+	var wg sync.WaitGroup
+	task := func() {
+		defer wg.Done()
+		n := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		<-block
+		atomic.AddInt32(&running, -1)
+	}
+
+	wg.Add(5)
+	for i := 0; i < 5; i++ {
+		if err := billing.Submit(task); err != nil {
+			t.Fatalf("submit %d: %v", i, err)
+		}
+	}
+	if err := billing.Submit(task); err != ErrQueueFull {
+		t.Fatalf("err = %v, want ErrQueueFull", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if s := billing.Stats(); s.Running != 2 || s.Queued != 3 || s.Rejected != 1 {
+		t.Fatalf("stats = %+v, want 2 running, 3 queued, 1 rejected", s)
+	}
+
+	close(block)
+	wg.Wait()
+	time.Sleep(10 * time.Millisecond)
+
+	if peak != 2 {
+		t.Fatalf("peak concurrency of tenant = %d, want 2", peak)
+	}
+	stats := p.TenantStats()
+	if len(stats) != 1 || stats[0].Completed != 5 || stats[0].Running != 0 {
+		t.Fatalf("stats = %+v, want 5 completed", stats)
+	}
+}
+
+func TestTenantShutdown(t *testing.T) {
+	p, _ := NewPool(4)
+	billing := p.Tenant("billing", 1, 0)
+
+	var ran int32
+	for i := 0; i < 5; i++ {
+		_ = billing.Submit(func() {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&ran, 1)
+		})
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran != 5 {
+		t.Fatalf("%d of 5 tenant tasks ran before Shutdown returned", ran)
+	}
+}
+
+func TestTenantDropped(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Close()
+	billing := p.Tenant("billing", 1, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	block := make(chan struct{})
+	done := make(chan struct{})
+	_ = billing.Submit(func() { <-block })
+	_ = billing.Submit(func() {
+		t.Error("dropped task ran")
+	}, WithContext(ctx))
+	_ = billing.Submit(func() { close(done) })
+	close(block)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("tenant stalled after a dropped task, stats %+v", billing.Stats())
+	}
+}
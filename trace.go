@@ -0,0 +1,63 @@
+package tinyPool
+
+import (
+	"context"
+	"runtime/trace"
+	"time"
+)
+
+// taskTrace is the runtime/trace task of a submitted task. It spans the
+// queue wait and the run, which is marked by an "execute" region. It is
+// nil while tracing is off.
+type taskTrace struct {
+	ctx  context.Context
+	task *trace.Task
+}
+
+// newTaskTrace starts the trace task of a task named name, if the
+// execution tracer is running.
+func newTaskTrace(name string) *taskTrace {
+	if !trace.IsEnabled() {
+		return nil
+	}
+	if name == "" {
+		name = "tinyPool.task"
+	}
+	ctx, task := trace.NewTask(context.Background(), name)
+	return &taskTrace{ctx: ctx, task: task}
+}
+
+// started logs how long the task waited in the queue.
+func (t *taskTrace) started(wait time.Duration) {
+	if t != nil {
+		trace.Log(t.ctx, "queue-wait", wait.String())
+	}
+}
+
+// region runs fn in the execute region of the task.
+func (t *taskTrace) region(fn func()) {
+	if t == nil {
+		fn()
+		return
+	}
+	trace.WithRegion(t.ctx, "execute", fn)
+}
+
+// end ends the trace task, logging err if the task was rejected.
+func (t *taskTrace) end(err error) {
+	if t == nil {
+		return
+	}
+	if err != nil {
+		trace.Log(t.ctx, "rejected", err.Error())
+	}
+	t.task.End()
+}
+
+// traced returns the trace task of item.
+func (p *engine[T]) traced(item T) *taskTrace {
+	if p.traceOf == nil {
+		return nil
+	}
+	return p.traceOf(item)
+}
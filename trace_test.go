@@ -0,0 +1,30 @@
+package tinyPool
+
+import (
+	"bytes"
+	"runtime/trace"
+	"sync"
+	"testing"
+)
+
+func TestTaskTrace(t *testing.T) {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skip("tracer busy:", err)
+	}
+
+	p, _ := NewPool(1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	_ = p.Submit(wg.Done, WithTaskName("export"))
+	wg.Wait()
+	p.Close()
+	_ = p.Submit(func() {}, WithTaskName("late"))
+	trace.Stop()
+
+	for _, s := range []string{"export", "queue-wait", "execute", "late", "rejected"} {
+		if !bytes.Contains(buf.Bytes(), []byte(s)) {
+			t.Errorf("trace has no %q", s)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package tinyPool
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	wheelBits   = 6
+	wheelSlots  = 1 << wheelBits
+	wheelMask   = wheelSlots - 1
+	wheelLevels = 5
+
+	// wheelTick is the resolution of scheduled tasks.
+	wheelTick = time.Millisecond
+)
+
+// timer is an entry of the timing wheel.
+type timer struct {
+	// expire and period are counted in ticks, period is 0 for one-shot timers
+	expire int64
+	period int64
+
+	fn func()
+
+	// drop, if set, is called instead of fn when the wheel drops the
+	// timer on close
+	drop func()
+
+	// list is the head of the slot holding the timer, nil once it's not
+	// pending anymore
+	list       **timer
+	prev, next *timer
+}
+
+// timingWheel runs callbacks after a delay. Timers are kept in hierarchical
+// wheels of slots, so pending timers cost neither a goroutine nor a runtime
+// timer each. A single goroutine runs while any timer is pending, asleep
+// until the next tick with work to do.
+type timingWheel struct {
+	mu sync.Mutex
+
+	slots [wheelLevels][wheelSlots]*timer
+
+	start time.Time
+
+	// now is the last tick processed
+	now int64
+
+	count   int
+	running bool
+
+	// sleepUntil is the tick the running goroutine sleeps until, wake
+	// wakes it up earlier
+	sleepUntil int64
+	wake       chan struct{}
+
+	// closed reports whether pending timers have to be dropped
+	closed func() bool
+}
+
+func newTimingWheel(closed func() bool) *timingWheel {
+	return &timingWheel{
+		start:  time.Now(),
+		wake:   make(chan struct{}, 1),
+		closed: closed,
+	}
+}
+
+// schedule runs fn after d, and then every period if period > 0.
+func (w *timingWheel) schedule(d, period time.Duration, fn func()) *timer {
+	return w.scheduleOrDrop(d, period, fn, nil)
+}
+
+// scheduleOrDrop is schedule calling drop if the timer is dropped on close.
+func (w *timingWheel) scheduleOrDrop(d, period time.Duration, fn, drop func()) *timer {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.count == 0 {
+		// nothing pending, the wheel can jump to the present
+		w.now = w.elapsed()
+	}
+
+	// one extra tick, so the timer never fires early
+	t := &timer{
+		expire: w.elapsed() + ticks(d) + 1,
+		period: ticks(period),
+		fn:     fn,
+		drop:   drop,
+	}
+	if t.expire <= w.now {
+		t.expire = w.now + 1
+	}
+	w.add(t)
+	w.count++
+
+	if !w.running {
+		w.running = true
+		go w.run()
+	} else if t.expire < w.sleepUntil {
+		w.wakeUp()
+	}
+	return t
+}
+
+// wakeUp makes the running goroutine look at the wheel again, e.g. to drop
+// the timers once the wheel is closed.
+func (w *timingWheel) wakeUp() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// stop removes t from the wheel. It returns false if t is not pending.
+func (w *timingWheel) stop(t *timer) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if t.list == nil {
+		return false
+	}
+	w.remove(t)
+	w.count--
+	return true
+}
+
+func (w *timingWheel) run() {
+	w.mu.Lock()
+	w.sleepUntil = w.nextTick()
+	sleep := time.NewTimer(w.until(w.sleepUntil))
+	w.mu.Unlock()
+	defer sleep.Stop()
+
+	var due, dropped []*timer
+	for {
+		select {
+		case <-sleep.C:
+		case <-w.wake:
+			if !sleep.Stop() {
+				<-sleep.C
+			}
+		}
+
+		w.mu.Lock()
+		if w.closed() {
+			dropped = w.clear(dropped)
+		}
+		for target := w.elapsed(); w.now < target; {
+			// skip the ticks with nothing to do
+			next := w.nextTick()
+			if next > target {
+				w.now = target
+				break
+			}
+			w.now = next - 1
+			due = w.advance(due)
+		}
+		w.mu.Unlock()
+
+		for i, t := range dropped {
+			t.drop()
+			dropped[i] = nil
+		}
+		dropped = dropped[:0]
+		for i, t := range due {
+			t.fn()
+			due[i] = nil
+		}
+		due = due[:0]
+
+		w.mu.Lock()
+		if w.count == 0 {
+			w.running = false
+			w.mu.Unlock()
+			return
+		}
+		w.sleepUntil = w.nextTick()
+		sleep.Reset(w.until(w.sleepUntil))
+		w.mu.Unlock()
+	}
+}
+
+// nextTick returns the first tick after now at which a timer may expire
+// or has to be cascaded down into a finer wheel.
+func (w *timingWheel) nextTick() int64 {
+	next := w.now + 1
+	found := false
+	for lvl := 0; lvl < wheelLevels; lvl++ {
+		shift := wheelBits * lvl
+		slot := w.now >> shift
+		for i := int64(1); i <= wheelSlots; i++ {
+			if w.slots[lvl][(slot+i)&wheelMask] == nil {
+				continue
+			}
+			if tick := (slot + i) << shift; !found || tick < next {
+				next, found = tick, true
+			}
+			break
+		}
+	}
+	return next
+}
+
+// until returns the time left until tick.
+func (w *timingWheel) until(tick int64) time.Duration {
+	d := time.Until(w.start.Add(time.Duration(tick) * wheelTick))
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// advance moves the wheel one tick forward and appends the timers which
+// expire to due. Recurring timers are put back for their next run.
+func (w *timingWheel) advance(due []*timer) []*timer {
+	w.now++
+
+	// when a wheel wraps around, move the next slot of the coarser wheel
+	// down into the finer ones
+	for lvl := 1; lvl < wheelLevels; lvl++ {
+		if (w.now>>(wheelBits*(lvl-1)))&wheelMask != 0 {
+			break
+		}
+		w.cascade(lvl, int((w.now>>(wheelBits*lvl))&wheelMask))
+	}
+
+	head := &w.slots[0][w.now&wheelMask]
+	for t := *head; t != nil; t = *head {
+		w.remove(t)
+		if t.expire > w.now {
+			// parked at the top wheel because it was too far away
+			w.add(t)
+			continue
+		}
+
+		due = append(due, t)
+		if t.period > 0 {
+			t.expire += t.period
+			w.add(t)
+		} else {
+			w.count--
+		}
+	}
+	return due
+}
+
+// clear drops all pending timers and appends those with a drop callback
+// to dropped.
+func (w *timingWheel) clear(dropped []*timer) []*timer {
+	for lvl := range w.slots {
+		for slot := range w.slots[lvl] {
+			head := &w.slots[lvl][slot]
+			for t := *head; t != nil; t = *head {
+				w.remove(t)
+				if t.drop != nil {
+					dropped = append(dropped, t)
+				}
+			}
+		}
+	}
+	w.count = 0
+	return dropped
+}
+
+func (w *timingWheel) cascade(lvl, slot int) {
+	head := &w.slots[lvl][slot]
+	for t := *head; t != nil; t = *head {
+		w.remove(t)
+		w.add(t)
+	}
+}
+
+func (w *timingWheel) add(t *timer) {
+	expire := t.expire
+	delta := expire - w.now
+
+	lvl := 0
+	for lvl < wheelLevels-1 && delta >= 1<<(wheelBits*(lvl+1)) {
+		lvl++
+	}
+	if limit := int64(1)<<(wheelBits*wheelLevels) - 1; delta > limit {
+		expire = w.now + limit
+	}
+
+	head := &w.slots[lvl][(expire>>(wheelBits*lvl))&wheelMask]
+	t.list = head
+	t.prev = nil
+	t.next = *head
+	if t.next != nil {
+		t.next.prev = t
+	}
+	*head = t
+}
+
+func (w *timingWheel) remove(t *timer) {
+	if t.prev != nil {
+		t.prev.next = t.next
+	} else {
+		*t.list = t.next
+	}
+	if t.next != nil {
+		t.next.prev = t.prev
+	}
+	t.list, t.prev, t.next = nil, nil, nil
+}
+
+// elapsed returns the number of whole ticks since the wheel was created.
+func (w *timingWheel) elapsed() int64 {
+	return int64(time.Since(w.start) / wheelTick)
+}
+
+// ticks converts d to ticks, rounding up.
+func ticks(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + wheelTick - 1) / wheelTick)
+}
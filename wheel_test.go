@@ -0,0 +1,124 @@
+package tinyPool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTimingWheelAdvance(t *testing.T) {
+	w := newTimingWheel(func() bool { return false })
+	w.now = 12345
+
+	fired := map[int64]int64{}
+	expires := []int64{1, 2, 63, 64, 65, 100, 4095, 4096, 4097, 300000, 1 << 26}
+	for _, d := range expires {
+		d := d
+		tm := &timer{expire: w.now + d}
+		tm.fn = func() { fired[d] = w.now }
+		w.add(tm)
+		w.count++
+	}
+
+	var due []*timer
+	for w.count > 0 {
+		due = w.advance(due[:0])
+		for _, tm := range due {
+			tm.fn()
+		}
+	}
+
+	for _, d := range expires {
+		if got := fired[d] - 12345; got != d {
+			t.Errorf("timer due in %d ticks fired after %d", d, got)
+		}
+	}
+}
+
+func TestTimingWheelPeriodAndStop(t *testing.T) {
+	w := newTimingWheel(func() bool { return false })
+
+	runs := 0
+	tm := &timer{expire: 10, period: 10}
+	tm.fn = func() { runs++ }
+	w.add(tm)
+	w.count++
+
+	var due []*timer
+	for i := 0; i < 35; i++ {
+		due = w.advance(due[:0])
+		for _, tm := range due {
+			tm.fn()
+		}
+	}
+	if runs != 3 {
+		t.Fatalf("recurring timer ran %d times in 35 ticks, want 3", runs)
+	}
+
+	if !w.stop(tm) || w.stop(tm) {
+		t.Fatal("stop should succeed exactly once")
+	}
+	if w.count != 0 {
+		t.Fatalf("count = %d after stop, want 0", w.count)
+	}
+}
+
+func TestTimingWheelClosed(t *testing.T) {
+	var closed int32
+	w := newTimingWheel(func() bool { return atomic.LoadInt32(&closed) == 1 })
+
+	tm := w.schedule(time.Hour, 0, func() {})
+	atomic.StoreInt32(&closed, 1)
+	w.wakeUp()
+	for deadline := time.Now().Add(time.Second); ; {
+		w.mu.Lock()
+		running := w.running
+		w.mu.Unlock()
+		if !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("wheel still running after close")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if w.stop(tm) {
+		t.Fatal("stop of a timer dropped by close succeeded")
+	}
+	if w.count != 0 {
+		t.Fatalf("count = %d after close, want 0", w.count)
+	}
+}
+
+func TestTimingWheelSleeps(t *testing.T) {
+	var closed int32
+	w := newTimingWheel(func() bool { return atomic.LoadInt32(&closed) == 1 })
+	defer func() {
+		// stop the goroutine asleep until the far timer
+		atomic.StoreInt32(&closed, 1)
+		w.wakeUp()
+	}()
+
+	fired := make(chan time.Time, 1)
+	start := time.Now()
+	w.schedule(150*time.Millisecond, 0, func() { fired <- time.Now() })
+	w.schedule(time.Hour, 0, func() {})
+
+	time.Sleep(50 * time.Millisecond)
+	w.mu.Lock()
+	now := w.now
+	w.mu.Unlock()
+	if now != 0 {
+		t.Fatalf("wheel woke up at tick %d before its first timer", now)
+	}
+
+	select {
+	case at := <-fired:
+		if d := at.Sub(start); d < 150*time.Millisecond {
+			t.Fatalf("timer fired after %v, want 150ms", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timer didn't fire")
+	}
+}
@@ -0,0 +1,60 @@
+package tinyPool
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WorkerInfo describes a started worker and the task it is running.
+type WorkerInfo struct {
+	ID int `json:"id"`
+
+	// Busy tells whether the worker is running a task. Task and Tag are
+	// its name and tag, Started is when it started.
+	Busy    bool      `json:"busy"`
+	Task    string    `json:"task,omitempty"`
+	Tag     string    `json:"tag,omitempty"`
+	Started time.Time `json:"started"`
+}
+
+// workerSlot holds what a worker is doing for DumpWorkers.
+type workerSlot struct {
+	mu   sync.Mutex
+	info WorkerInfo
+}
+
+func (w *workerSlot) begin(task, tag string) {
+	w.mu.Lock()
+	w.info.Busy, w.info.Task, w.info.Tag, w.info.Started = true, task, tag, time.Now()
+	w.mu.Unlock()
+}
+
+func (w *workerSlot) end() {
+	w.mu.Lock()
+	w.info = WorkerInfo{ID: w.info.ID}
+	w.mu.Unlock()
+}
+
+// register adds a slot for a new worker.
+func (p *engine[T]) register() *workerSlot {
+	w := &workerSlot{info: WorkerInfo{ID: int(atomic.AddInt64(&p.workerSeq, 1))}}
+	p.workers.Store(w.info.ID, w)
+	return w
+}
+
+// DumpWorkers returns the started workers by ID, with the task each of
+// them is running, to tell what the pool is doing right now.
+func (p *engine[T]) DumpWorkers() []WorkerInfo {
+	var infos []WorkerInfo
+	p.workers.Range(func(_, v interface{}) bool {
+		w := v.(*workerSlot)
+		w.mu.Lock()
+		infos = append(infos, w.info)
+		w.mu.Unlock()
+		return true
+	})
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
@@ -0,0 +1,31 @@
+package tinyPool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDumpWorkers(t *testing.T) {
+	p, _ := NewPool(2, WithPreAlloc(2))
+	defer p.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+	before := time.Now()
+	_ = p.Submit(func() { <-release }, WithTaskName("export"), WithTag("db"))
+	time.Sleep(20 * time.Millisecond)
+
+	infos := p.DumpWorkers()
+	if len(infos) != 2 {
+		t.Fatalf("workers = %+v, want 2", infos)
+	}
+	var busy []WorkerInfo
+	for _, w := range infos {
+		if w.Busy {
+			busy = append(busy, w)
+		}
+	}
+	if len(busy) != 1 || busy[0].Task != "export" || busy[0].Tag != "db" || busy[0].Started.Before(before) {
+		t.Fatalf("busy workers = %+v", busy)
+	}
+}